@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
@@ -17,6 +18,7 @@ type AppConfig struct {
 	JWTSecret   string
 	Environment string
 	BaseURL     string
+	FrontendURL string // Public pages tenants open from messages and QR codes
 
 	//Razorpay Credentials
 	RazorpayKeyID      string
@@ -27,6 +29,55 @@ type AppConfig struct {
 	TwilioSID        string
 	TwilioAuthToken  string
 	TwilioFromNumber string
+	TwilioSMSFrom    string
+
+	// SMTP (email notifications)
+	SMTPHost string
+	SMTPPort string
+	SMTPUser string
+	SMTPPass string
+	SMTPFrom string
+
+	// Notifications: "live" sends for real, "log" writes to the log/file sink, "capture" keeps an in-memory outbox
+	NotifierMode  string
+	NotifyLogFile string
+
+	// Outbox workers
+	OutboxWorkers     int
+	OutboxMaxAttempts int
+
+	// Announcements: per-property hourly cap and how fast the outbox releases a broadcast
+	AnnouncementsPerHour int
+	AnnouncementPerMin   int
+
+	// Complaints one tenant may raise in any 24 hours; 0 turns the limit off
+	ComplaintsPerDay int
+
+	// Size caps for complaint photos and videos, in MB
+	ComplaintImageMaxMB int
+	ComplaintVideoMaxMB int
+	// Total size of the files on one complaint from the public QR form, in MB
+	ComplaintPublicMaxMB int
+
+	// Days after resolution a tenant may still reopen a complaint from the rating link
+	ComplaintReopenDays int
+
+	// File storage: "local" (StorageDir) or "s3" (any S3-compatible store, e.g. MinIO)
+	StorageDriver  string
+	StorageDir     string
+	S3Endpoint     string
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3PathStyle    bool
+	FileSigningKey string // Signs download links; defaults to JWT_SECRET
+	DownloadTTL    int    // Hours a receipt/invoice link stays valid
+
+	// Scheduler: set SCHEDULER_ENABLED=false on replicas that should only serve HTTP
+	SchedulerEnabled     bool
+	SchedulerTimezone    string
+	ReceiptRetentionDays int
 }
 
 var App AppConfig
@@ -50,6 +101,7 @@ func LoadConfig() {
 		JWTSecret:   getEnv("JWT_SECRET", "placeholder_for_dev_only"),
 		Environment: getEnv("APP_ENV", "development"),
 		BaseURL:     getEnv("BASE_URL", "http://localhost:8080"),
+		FrontendURL: getEnv("FRONTEND_URL", "http://localhost:5173"),
 
 		RazorpayKeyID:      getEnv("RAZORPAY_KEY_ID", ""),
 		RazorpayKeySecret:  getEnv("RAZORPAY_KEY_SECRET", ""),
@@ -58,6 +110,60 @@ func LoadConfig() {
 		TwilioSID:        getEnv("TWILIO_ACCOUNT_SID", ""),
 		TwilioAuthToken:  getEnv("TWILIO_AUTH_TOKEN", ""),
 		TwilioFromNumber: getEnv("TWILIO_FROM_NUMBER", ""),
+		TwilioSMSFrom:    getEnv("TWILIO_SMS_FROM", ""),
+
+		SMTPHost: getEnv("SMTP_HOST", ""),
+		SMTPPort: getEnv("SMTP_PORT", "587"),
+		SMTPUser: getEnv("SMTP_USER", ""),
+		SMTPPass: getEnv("SMTP_PASS", ""),
+		SMTPFrom: getEnv("SMTP_FROM", ""),
+
+		NotifyLogFile: getEnv("NOTIFY_LOG_FILE", ""),
+
+		OutboxWorkers:     getEnvInt("OUTBOX_WORKERS", 2),
+		OutboxMaxAttempts: getEnvInt("OUTBOX_MAX_ATTEMPTS", 5),
+
+		AnnouncementsPerHour: getEnvInt("ANNOUNCEMENTS_PER_HOUR", 5),
+		AnnouncementPerMin:   getEnvInt("ANNOUNCEMENT_MESSAGES_PER_MIN", 30),
+
+		ComplaintsPerDay:     getEnvInt("COMPLAINTS_PER_TENANT_PER_DAY", 1),
+		ComplaintImageMaxMB:  getEnvInt("COMPLAINT_IMAGE_MAX_MB", 8),
+		ComplaintVideoMaxMB:  getEnvInt("COMPLAINT_VIDEO_MAX_MB", 30),
+		ComplaintPublicMaxMB: getEnvInt("COMPLAINT_PUBLIC_MAX_MB", 40),
+		ComplaintReopenDays:  getEnvInt("COMPLAINT_REOPEN_DAYS", 7),
+
+		StorageDriver:  getEnv("STORAGE_DRIVER", "local"),
+		StorageDir:     getEnv("STORAGE_DIR", "data/files"),
+		S3Endpoint:     getEnv("S3_ENDPOINT", ""),
+		S3Region:       getEnv("S3_REGION", "ap-south-1"),
+		S3Bucket:       getEnv("S3_BUCKET", ""),
+		S3AccessKey:    getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:    getEnv("S3_SECRET_KEY", ""),
+		S3PathStyle:    getEnv("S3_PATH_STYLE", "false") == "true",
+		FileSigningKey: getEnv("FILE_SIGNING_KEY", ""),
+		DownloadTTL:    getEnvInt("DOWNLOAD_LINK_TTL_HOURS", 168),
+
+		SchedulerEnabled:     getEnv("SCHEDULER_ENABLED", "true") == "true",
+		SchedulerTimezone:    getEnv("SCHEDULER_TIMEZONE", "Asia/Kolkata"),
+		ReceiptRetentionDays: getEnvInt("RECEIPT_RETENTION_DAYS", 90),
+	}
+
+	// Real messages only go out in production unless explicitly asked for
+	defaultNotifier := "log"
+	if App.Environment == "production" {
+		defaultNotifier = "live"
+	}
+	App.NotifierMode = getEnv("NOTIFIER_MODE", defaultNotifier)
+	if App.NotifierMode != "live" && App.NotifierMode != "log" && App.NotifierMode != "capture" {
+		log.Fatalf("❌ Unknown NOTIFIER_MODE %q: use live, log or capture", App.NotifierMode)
+	}
+
+	// Links in messages, QR posters and receipts are built from these; localhost only works on this machine
+	if App.BaseURL == "http://localhost:8080" {
+		log.Println("⚠️ WARNING: BASE_URL is the localhost default; links to files and webhooks will not work for tenants.")
+	}
+	if App.FrontendURL == "http://localhost:5173" {
+		log.Println("⚠️ WARNING: FRONTEND_URL is the localhost default; QR posters and feedback links will not open for tenants.")
 	}
 
 	// 2. Production Security Warnings
@@ -75,10 +181,19 @@ func LoadConfig() {
 	log.Printf("✅ Configuration loaded successfully for [%s] mode", App.Environment)
 }
 
-// getEnv checks if an environment variable exists, otherwise returns a fallback value
+// getEnv treats an empty variable as unset: docker-compose passes "${VAR}" through as "" when the host lacks it
 func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
+	if value, exists := os.LookupEnv(key); exists && value != "" {
 		return value
 	}
 	return defaultValue
 }
+
+// getEnvInt is getEnv for numeric settings; unparsable values fall back to the default
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"pg-manager-backend/models"
+	"pg-manager-backend/utils"
 
 	"gorm.io/driver/postgres" // Changed from mysql
 	"gorm.io/gorm"
@@ -17,7 +18,7 @@ func ConnectDatabase() {
 		App.DBHost, App.DBUser, App.DBPass, App.DBName, App.DBPort)
 
 	// 2. Open connection using the Postgres driver
-	database, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	database, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 
 	if err != nil {
 		log.Fatal("❌ Failed to connect to database: ", err)
@@ -31,15 +32,81 @@ func ConnectDatabase() {
 		&models.Room{},
 		&models.TenantProfile{},
 		&models.Complaint{},
+		&models.ComplaintEvent{},
+		&models.ComplaintSLA{},
+		&models.ComplaintAttachment{},
+		&models.Vendor{},
+		&models.WorkOrder{},
 		&models.Expenditure{},
 		&models.Payment{},
 		&models.ArchivedTenant{},
+		&models.Invoice{},
+		&models.PaymentAllocation{},
+		&models.BankStatement{},
+		&models.BankStatementLine{},
+		&models.OutboundMessage{},
+		&models.MessageTemplate{},
+		&models.InboundMessage{},
+		&models.ChatSession{},
+		&models.RentReminder{},
+		&models.JobRun{},
+		&models.OwnerAlertSettings{},
+		&models.ConsentEvent{},
+		&models.NumberSeries{},
+		&models.Announcement{},
+		&models.AnnouncementRecipient{},
 	)
 
 	if err != nil {
 		log.Fatal("❌ Migration Error:", err)
 	}
 
+	// 4. Data fixes AutoMigrate cannot express; each is a no-op once applied
+	// Reminders used to be unique per tenant and due date, which dropped one of two invoices due the same day
+	if database.Migrator().HasIndex(&models.RentReminder{}, "idx_reminder_once") {
+		database.Migrator().DropIndex(&models.RentReminder{}, "idx_reminder_once")
+	}
+	// One live payment per UTR / gateway reference; reversing a payment frees its reference again
+	if err := database.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_reference_live ON payments (reference)
+		WHERE reference <> '' AND status <> 'reversed'`).Error; err != nil {
+		log.Printf("⚠️ Could not add the unique UTR index, resolve duplicate payment references first: %v", err)
+	}
+	database.Model(&models.Complaint{}).Where("status = ?", "Pending").Update("status", "open")
+	database.Model(&models.Complaint{}).Where("status = ?", "Resolved").Update("status", "resolved")
+	normalizeStoredPhones(database)
+	database.Exec(`UPDATE complaints SET tenant_id = tenant_profiles.user_id, room_id = tenant_profiles.room_id
+		FROM tenant_profiles
+		WHERE COALESCE(complaints.tenant_id, 0) = 0 AND complaints.phone_number = tenant_profiles.phone_number
+		AND complaints.property_id = tenant_profiles.property_id`)
+
 	DB = database
 	fmt.Println("✅ Database connection and migrations successful")
 }
+
+// normalizeStoredPhones rewrites numbers saved before phones were kept in E.164 ("9845012345" → "+919845012345"),
+// so lookups by the normalized number still find tenants onboarded earlier. A number that does not parse, or
+// whose E.164 form another row already has, is left as it is and logged.
+func normalizeStoredPhones(db *gorm.DB) {
+	columns := []struct{ table, column string }{
+		{"users", "phone"},
+		{"tenant_profiles", "phone_number"},
+		{"complaints", "phone_number"},
+	}
+	for _, c := range columns {
+		var rows []struct {
+			ID    uint
+			Phone string
+		}
+		db.Table(c.table).Select("id, "+c.column+" AS phone").Where(c.column+" <> '' AND "+c.column+" NOT LIKE ?", "+%").Scan(&rows)
+
+		for _, row := range rows {
+			phone, err := utils.NormalizePhone(row.Phone)
+			if err != nil {
+				continue
+			}
+			if err := db.Table(c.table).Where("id = ?", row.ID).Update(c.column, phone).Error; err != nil {
+				log.Printf("⚠️ Could not normalize %s.%s for id %d: %v", c.table, c.column, row.ID, err)
+			}
+		}
+	}
+}
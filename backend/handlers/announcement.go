@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"pg-manager-backend/services"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SendAnnouncement handles POST /api/properties/:id/announcements
+func SendAnnouncement(c *gin.Context) {
+	var input services.AnnouncementInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var propertyID uint
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	announcement, err := services.SendAnnouncement(propertyID, ownerID, input)
+	if err != nil {
+		switch {
+		case err.Error() == "unauthorized: you do not own this property":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case strings.HasPrefix(err.Error(), "rate limit"):
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, announcement)
+}
+
+// GetAnnouncements handles GET /api/properties/:id/announcements
+func GetAnnouncements(c *gin.Context) {
+	var propertyID uint
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	announcements, err := services.GetAnnouncements(propertyID, ownerID)
+	if err != nil {
+		if err.Error() == "unauthorized: you do not own this property" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, announcements)
+}
+
+// GetAnnouncementDetail handles GET /api/announcements/:id
+func GetAnnouncementDetail(c *gin.Context) {
+	var announcementID uint
+	fmt.Sscanf(c.Param("id"), "%d", &announcementID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	detail, err := services.GetAnnouncementDetail(announcementID, ownerID)
+	if err != nil {
+		switch err.Error() {
+		case "unauthorized: you do not own this property":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case "announcement not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
@@ -12,7 +12,6 @@ func RegisterUser(c *gin.Context) {
 		Name     string `json:"name" binding:"required"`
 		Email    string `json:"email" binding:"required"`
 		Password string `json:"password" binding:"required"`
-		Role     string `json:"role" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -21,7 +20,7 @@ func RegisterUser(c *gin.Context) {
 	}
 
 	// Call Service
-	err := services.RegisterUser(input.Name, input.Email, input.Password, input.Role)
+	err := services.RegisterUser(input.Name, input.Email, input.Password)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
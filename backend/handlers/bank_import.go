@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"pg-manager-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Bank exports for a month are a few hundred KB at most
+const maxStatementSize = 5 << 20
+
+// UploadBankStatement handles POST /api/bank-statements (multipart: property_id, file)
+func UploadBankStatement(c *gin.Context) {
+	var propertyID uint
+	fmt.Sscanf(c.PostForm("property_id"), "%d", &propertyID)
+	if propertyID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "property_id is required"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Statement file is required"})
+		return
+	}
+	if fileHeader.Size > maxStatementSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Statement file is too large (max 5 MB)"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Could not read uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Could not read uploaded file"})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	statement, skipped, err := services.ImportBankStatement(propertyID, ownerID, fileHeader.Filename, data)
+	if err != nil {
+		respondBankError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":   "Statement imported. Review the matched credits before posting.",
+		"statement": statement,
+		"skipped":   skipped,
+	})
+}
+
+// GetBankReviewQueue handles GET /api/bank-statements/review?property_id=1
+func GetBankReviewQueue(c *gin.Context) {
+	var propertyID uint
+	fmt.Sscanf(c.Query("property_id"), "%d", &propertyID)
+	if propertyID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "property_id is required"})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	lines, err := services.GetBankReviewQueue(propertyID, ownerID)
+	if err != nil {
+		respondBankError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lines)
+}
+
+// ConfirmBankLine handles POST /api/bank-statements/lines/:id/confirm
+func ConfirmBankLine(c *gin.Context) {
+	var lineID uint
+	fmt.Sscanf(c.Param("id"), "%d", &lineID)
+
+	// tenant_id is optional: leave it out to accept the suggested tenant
+	var input struct {
+		TenantID uint `json:"tenant_id"`
+	}
+	c.ShouldBindJSON(&input)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	payment, newBalance, err := services.ConfirmBankLine(lineID, ownerID, input.TenantID)
+	if err != nil {
+		respondBankError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Bank credit posted as payment",
+		"payment":     payment,
+		"new_balance": newBalance,
+	})
+}
+
+// ConfirmSuggestedBankLines handles POST /api/bank-statements/:id/confirm-suggested
+func ConfirmSuggestedBankLines(c *gin.Context) {
+	var statementID uint
+	fmt.Sscanf(c.Param("id"), "%d", &statementID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	posted, failures := services.ConfirmSuggestedBankLines(statementID, ownerID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"posted":   posted,
+		"failures": failures,
+	})
+}
+
+// IgnoreBankLine handles POST /api/bank-statements/lines/:id/ignore
+func IgnoreBankLine(c *gin.Context) {
+	var lineID uint
+	fmt.Sscanf(c.Param("id"), "%d", &lineID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	if err := services.IgnoreBankLine(lineID, ownerID); err != nil {
+		respondBankError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Statement line ignored"})
+}
+
+// respondBankError maps import/review failures to status codes
+func respondBankError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "unauthorized: you do not own this property":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "statement line not found", "tenant profile not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "utr already recorded", "statement line is already posted":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
@@ -1,58 +1,483 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"pg-manager-backend/models"
 	"pg-manager-backend/services"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// PublicRaiseComplaint is the endpoint for the QR code form
+// complaintFormOverhead leaves room for the text fields and multipart boundaries next to the files
+const complaintFormOverhead = 1 << 20
+
+// limitComplaintBody stops reading a multipart body once it could not be a valid upload, instead of
+// spooling it all to disk. Call it before binding any form fields so the limit applies while the form is parsed.
+func limitComplaintBody(c *gin.Context, fileBytes int64) {
+	if c.ContentType() == "multipart/form-data" {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, fileBytes+complaintFormOverhead)
+	}
+}
+
+// readComplaintUploads collects the "media" files of a multipart request; JSON requests have none.
+// Owner paths allow MaxComplaintUploads full-size files. Call it before reading any form fields.
+func readComplaintUploads(c *gin.Context) ([]services.ComplaintUpload, error) {
+	if c.ContentType() != "multipart/form-data" {
+		return nil, nil
+	}
+	limitComplaintBody(c, services.MaxComplaintUploads*services.MaxComplaintFileBytes())
+	return complaintFormFiles(c)
+}
+
+// complaintFormFiles reads the "media" files of a multipart body already limited by limitComplaintBody
+func complaintFormFiles(c *gin.Context) ([]services.ComplaintUpload, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, errors.New("invalid upload: request is too large")
+		}
+		return nil, errors.New("invalid upload: could not read form")
+	}
+	if len(form.File["media"]) > services.MaxComplaintUploads {
+		return nil, fmt.Errorf("invalid attachments: at most %d files at a time", services.MaxComplaintUploads)
+	}
+
+	var uploads []services.ComplaintUpload
+	for _, fileHeader := range form.File["media"] {
+		// Anything bigger than the largest allowed video is refused before it is read into memory
+		if fileHeader.Size > services.MaxComplaintFileBytes() {
+			return nil, fmt.Errorf("invalid attachment %s: file is too large", fileHeader.Filename)
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, errors.New("invalid upload: could not read " + fileHeader.Filename)
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil {
+			return nil, errors.New("invalid upload: could not read " + fileHeader.Filename)
+		}
+		uploads = append(uploads, services.ComplaintUpload{FileName: fileHeader.Filename, Data: data})
+	}
+	return uploads, nil
+}
+
+// PublicRaiseComplaint is the endpoint for the QR code form. It takes JSON, or multipart/form-data with the
+// same fields plus up to five "media" photos/videos. The token from the poster's QR code names the property.
 func PublicRaiseComplaint(c *gin.Context) {
-	var input models.Complaint
-	if err := c.ShouldBindJSON(&input); err != nil {
+	var input struct {
+		Token       string `json:"token" form:"token" binding:"required"`
+		RoomNo      string `json:"room_no" form:"room_no"` // Fixed by the token on room posters
+		PhoneNumber string `json:"phone_number" form:"phone_number" binding:"required"`
+		Category    string `json:"category" form:"category" binding:"required"`
+		Description string `json:"description" form:"description" binding:"required"`
+	}
+	// Bind the text fields first, within the smaller public limit; the files are only opened
+	// once the token, phone and room check out
+	limitComplaintBody(c, services.MaxPublicComplaintBytes())
+	if err := c.ShouldBind(&input); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid upload: request is too large"})
+			return
+		}
 		c.JSON(400, gin.H{"error": "Invalid input"})
 		return
 	}
 
-	err := services.RegisterComplaint(input)
+	data, err := services.CheckQRComplaint(input.Token, models.Complaint{
+		RoomNo:      input.RoomNo,
+		PhoneNumber: input.PhoneNumber,
+		Category:    input.Category,
+		Description: input.Description,
+	})
 	if err != nil {
-		if err.Error() == "tenant_not_verified" {
-			// This triggers the "Verification Failed" message in your Vue app
-			c.JSON(403, gin.H{"error": "Phone number not registered with this property"})
+		respondQRComplaintError(c, err)
+		return
+	}
+
+	var uploads []services.ComplaintUpload
+	if c.ContentType() == "multipart/form-data" {
+		if uploads, err = complaintFormFiles(c); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		c.JSON(500, gin.H{"error": "Internal server error"})
+	}
+
+	complaint, err := services.RegisterComplaint(data, uploads...)
+	if err != nil {
+		respondQRComplaintError(c, err)
 		return
 	}
 
-	c.JSON(200, gin.H{"message": "Complaint submitted successfully"})
+	c.JSON(200, gin.H{"message": "Complaint submitted successfully", "complaint_id": complaint.ID})
+}
+
+// respondQRComplaintError maps public form failures to status codes
+func respondQRComplaintError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "invalid qr code":
+		c.JSON(http.StatusForbidden, gin.H{"error": "This QR code is no longer valid. Please ask your PG owner for the current one."})
+	case err.Error() == "tenant_not_verified":
+		// This triggers the "Verification Failed" message in your Vue app
+		c.JSON(403, gin.H{"error": "Phone number and room do not match a tenant of this property"})
+	case strings.HasPrefix(err.Error(), "rate limit"):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "invalid"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(500, gin.H{"error": "Internal server error"})
+	}
+}
+
+// respondComplaintError maps complaint workflow failures to status codes
+func respondComplaintError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "unauthorized: you do not own this property":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err.Error() == "complaint not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "invalid"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
 }
 
-// GetComplaints fetches all complaints for a specific property
+// GetComplaints handles GET /api/complaints?property_id=&status=&overdue=true
 func GetComplaints(c *gin.Context) {
-	propertyID := c.Query("property_id")
-	if propertyID == "" {
+	var propertyID uint
+	fmt.Sscanf(c.Query("property_id"), "%d", &propertyID)
+	if propertyID == 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Property ID is required"})
 		return
 	}
 
-	complaints, err := services.GetAllComplaints(propertyID)
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	complaints, err := services.GetAllComplaints(propertyID, ownerID, c.Query("status"), c.Query("overdue") == "true")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch complaints"})
+		respondComplaintError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, complaints)
 }
 
-// MarkComplaintResolved updates the status so the owner can clear their dashboard
+// GetComplaint handles GET /api/complaints/:id
+func GetComplaint(c *gin.Context) {
+	var complaintID uint
+	fmt.Sscanf(c.Param("id"), "%d", &complaintID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	detail, err := services.GetComplaintDetail(complaintID, ownerID)
+	if err != nil {
+		respondComplaintError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, detail)
+}
+
+// UpdateComplaintStatus handles PUT /api/complaints/:id/status
+func UpdateComplaintStatus(c *gin.Context) {
+	var complaintID uint
+	fmt.Sscanf(c.Param("id"), "%d", &complaintID)
+
+	var input struct {
+		Status  string `json:"status" binding:"required"`
+		Comment string `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	complaint, err := services.UpdateComplaintStatus(complaintID, ownerID, input.Status, input.Comment)
+	if err != nil {
+		respondComplaintError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, complaint)
+}
+
+// MarkComplaintResolved handles PUT /api/complaints/:id/resolve. An optional multipart body carries a
+// "comment" and "media" files, stored as "after" photos proving the fix.
 func MarkComplaintResolved(c *gin.Context) {
-	id := c.Param("id")
-	if err := services.ResolveComplaint(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Update failed"})
+	var complaintID uint
+	fmt.Sscanf(c.Param("id"), "%d", &complaintID)
+
+	uploads, err := readComplaintUploads(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	if _, err := services.ResolveComplaint(complaintID, ownerID, c.PostForm("comment"), uploads); err != nil {
+		respondComplaintError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Complaint marked as resolved"})
 }
+
+// AssignComplaint handles PUT /api/complaints/:id/assign
+func AssignComplaint(c *gin.Context) {
+	var complaintID uint
+	fmt.Sscanf(c.Param("id"), "%d", &complaintID)
+
+	var input struct {
+		AssigneeType string `json:"assignee_type" binding:"required"` // staff or vendor
+		Name         string `json:"name" binding:"required"`
+		Phone        string `json:"phone"`
+		Comment      string `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	complaint, err := services.AssignComplaint(complaintID, ownerID, input.AssigneeType, input.Name, input.Phone, input.Comment)
+	if err != nil {
+		respondComplaintError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, complaint)
+}
+
+// UpdateComplaintPriority handles PUT /api/complaints/:id/priority
+func UpdateComplaintPriority(c *gin.Context) {
+	var complaintID uint
+	fmt.Sscanf(c.Param("id"), "%d", &complaintID)
+
+	var input struct {
+		Priority string `json:"priority" binding:"required"`
+		Comment  string `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	complaint, err := services.UpdateComplaintPriority(complaintID, ownerID, input.Priority, input.Comment)
+	if err != nil {
+		respondComplaintError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, complaint)
+}
+
+// AddComplaintComment handles POST /api/complaints/:id/comments
+func AddComplaintComment(c *gin.Context) {
+	var complaintID uint
+	fmt.Sscanf(c.Param("id"), "%d", &complaintID)
+
+	var input struct {
+		Comment string `json:"comment" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	event, err := services.AddComplaintComment(complaintID, ownerID, input.Comment)
+	if err != nil {
+		respondComplaintError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, event)
+}
+
+// AddComplaintAttachments handles POST /api/complaints/:id/attachments (multipart: stage=before|after, media files)
+func AddComplaintAttachments(c *gin.Context) {
+	var complaintID uint
+	fmt.Sscanf(c.Param("id"), "%d", &complaintID)
+
+	uploads, err := readComplaintUploads(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	stage := c.DefaultPostForm("stage", "after")
+	attachments, err := services.AddComplaintAttachments(complaintID, ownerID, stage, uploads)
+	if err != nil {
+		respondComplaintError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, attachments)
+}
+
+// GetComplaintSLA handles GET /api/properties/:id/complaint-sla
+func GetComplaintSLA(c *gin.Context) {
+	var propertyID uint
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	hours, err := services.GetComplaintSLAs(propertyID, ownerID)
+	if err != nil {
+		respondComplaintError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hours)
+}
+
+// UpdateComplaintSLA handles PUT /api/properties/:id/complaint-sla with {"Plumbing": 24, ...}
+func UpdateComplaintSLA(c *gin.Context) {
+	var propertyID uint
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+
+	var input map[string]int
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expected resolution hours per category"})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	hours, err := services.UpdateComplaintSLAs(propertyID, ownerID, input)
+	if err != nil {
+		respondComplaintError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, hours)
+}
+
+// respondFeedbackError maps public feedback-link failures to status codes
+func respondFeedbackError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "invalid link":
+		c.JSON(http.StatusForbidden, gin.H{"error": "This link is not valid"})
+	case err.Error() == "complaint not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "cannot"):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "invalid"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+	}
+}
+
+// GetComplaintFeedback handles GET /api/public/complaints/:id/feedback?sig= (public; the signed link is the credential)
+func GetComplaintFeedback(c *gin.Context) {
+	var complaintID uint
+	fmt.Sscanf(c.Param("id"), "%d", &complaintID)
+
+	feedback, err := services.GetComplaintFeedback(complaintID, c.Query("sig"))
+	if err != nil {
+		respondFeedbackError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, feedback)
+}
+
+// RateComplaint handles POST /api/public/complaints/:id/rating?sig= {"rating": 1-5, "comment": "..."}
+func RateComplaint(c *gin.Context) {
+	var complaintID uint
+	fmt.Sscanf(c.Param("id"), "%d", &complaintID)
+
+	var input struct {
+		Rating  int    `json:"rating" binding:"required"`
+		Comment string `json:"comment"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Rating is required"})
+		return
+	}
+
+	feedback, err := services.RateComplaint(complaintID, c.Query("sig"), input.Rating, input.Comment)
+	if err != nil {
+		respondFeedbackError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, feedback)
+}
+
+// ReopenComplaint handles POST /api/public/complaints/:id/reopen?sig= {"reason": "..."}
+func ReopenComplaint(c *gin.Context) {
+	var complaintID uint
+	fmt.Sscanf(c.Param("id"), "%d", &complaintID)
+
+	var input struct {
+		Reason string `json:"reason"`
+	}
+	c.ShouldBindJSON(&input)
+
+	feedback, err := services.ReopenComplaint(complaintID, c.Query("sig"), input.Reason)
+	if err != nil {
+		respondFeedbackError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, feedback)
+}
+
+// GetComplaintReport handles GET /api/complaints/report?property_id=&from=YYYY-MM-DD&to=YYYY-MM-DD
+// Without a property it covers all the owner's properties; without dates, the last three months.
+func GetComplaintReport(c *gin.Context) {
+	var propertyID uint
+	fmt.Sscanf(c.Query("property_id"), "%d", &propertyID)
+
+	to := time.Now()
+	from := to.AddDate(0, -3, 0)
+	for param, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Dates must be in YYYY-MM-DD format"})
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	report, err := services.GetComplaintReport(ownerID, propertyID, from, to)
+	if err != nil {
+		respondComplaintError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
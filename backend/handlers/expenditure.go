@@ -16,6 +16,10 @@ func AddExpense(c *gin.Context) {
 	}
 
 	if err := services.RecordExpense(input); err != nil {
+		if err.Error() == "invalid complaint for this property" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record expense"})
 		return
 	}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"pg-manager-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetOpenInvoices handles GET /api/invoices/open?property_id=1 for the UPI reconciliation screen
+func GetOpenInvoices(c *gin.Context) {
+	var propertyID uint
+	fmt.Sscanf(c.Query("property_id"), "%d", &propertyID)
+	if propertyID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "property_id is required"})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	invoices, err := services.GetOpenInvoices(propertyID, ownerID)
+	if err != nil {
+		if err.Error() == "unauthorized: you do not own this property" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch open invoices"})
+		return
+	}
+
+	c.JSON(http.StatusOK, invoices)
+}
+
+// MatchUPIPayment handles POST /api/payments/upi/match and suggests invoices for a UTR
+func MatchUPIPayment(c *gin.Context) {
+	var input struct {
+		PropertyID uint    `json:"property_id" binding:"required"`
+		UTR        string  `json:"utr" binding:"required"`
+		Amount     float64 `json:"amount" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input format"})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	matches, err := services.MatchUPIPayment(input.PropertyID, ownerID, input.UTR, input.Amount)
+	if err != nil {
+		respondUPIError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"matches": matches})
+}
+
+// ReconcileUPIPayment handles POST /api/payments/upi/reconcile
+func ReconcileUPIPayment(c *gin.Context) {
+	var input struct {
+		InvoiceID uint    `json:"invoice_id" binding:"required"`
+		UTR       string  `json:"utr" binding:"required"`
+		Amount    float64 `json:"amount" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input format"})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	payment, newBalance, err := services.ReconcileUPIPayment(ownerID, input.InvoiceID, input.UTR, input.Amount)
+	if err != nil {
+		respondUPIError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "UPI payment reconciled successfully",
+		"payment":     payment,
+		"new_balance": newBalance,
+	})
+}
+
+// respondUPIError maps reconciliation failures to status codes
+func respondUPIError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "unauthorized: you do not own this property":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "invoice not found", "tenant profile not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "utr already recorded", "invoice is already paid":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// GetTenantAccount handles GET /api/tenants/:id/account (open invoices + carry-forward credit)
+func GetTenantAccount(c *gin.Context) {
+	var tenantID uint
+	fmt.Sscanf(c.Param("id"), "%d", &tenantID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	account, err := services.GetTenantAccount(tenantID, ownerID)
+	if err != nil {
+		respondUPIError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, account)
+}
+
+// AllocateTenantCredit handles POST /api/tenants/:id/allocate-credit {invoice_ids: [...]}
+func AllocateTenantCredit(c *gin.Context) {
+	var tenantID uint
+	fmt.Sscanf(c.Param("id"), "%d", &tenantID)
+
+	var input struct {
+		InvoiceIDs []uint `json:"invoice_ids" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invoice_ids is required"})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	account, err := services.AllocateCredit(tenantID, ownerID, input.InvoiceIDs)
+	if err != nil {
+		respondUPIError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Credit applied to selected invoices",
+		"account": account,
+	})
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"pg-manager-backend/scheduler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jobOwnerScope returns whose properties the caller's job views and runs cover: 0 (every owner) for
+// admins, their own for owners. Anyone else gets a 403 and ok=false.
+func jobOwnerScope(c *gin.Context) (uint, bool) {
+	role, _ := c.Get("role")
+	userIDFloat, _ := c.Get("user_id")
+	userID, _ := userIDFloat.(float64)
+
+	switch role {
+	case "admin":
+		return 0, true
+	case "owner":
+		return uint(userID), true
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": "only owners and admins can manage scheduled jobs"})
+	return 0, false
+}
+
+// GetJobStatus handles GET /api/jobs
+func GetJobStatus(c *gin.Context) {
+	ownerID, ok := jobOwnerScope(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, scheduler.Status(ownerID))
+}
+
+// GetJobHistory handles GET /api/jobs/:name/runs?limit=50
+func GetJobHistory(c *gin.Context) {
+	ownerID, ok := jobOwnerScope(c)
+	if !ok {
+		return
+	}
+
+	var limit int
+	fmt.Sscanf(c.Query("limit"), "%d", &limit)
+
+	runs, err := scheduler.History(c.Param("name"), limit, ownerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
+
+// TriggerJob handles POST /api/jobs/:name/run
+func TriggerJob(c *gin.Context) {
+	ownerID, ok := jobOwnerScope(c)
+	if !ok {
+		return
+	}
+
+	userIDFloat, _ := c.Get("user_id")
+	userID := uint(userIDFloat.(float64))
+
+	if err := scheduler.RunNow(c.Param("name"), userID, ownerID); err != nil {
+		switch err.Error() {
+		case "job not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		case "only admins can run this job":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Job started; check /api/jobs/" + c.Param("name") + "/runs for the result"})
+}
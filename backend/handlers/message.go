@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"pg-manager-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetOutboundMessages handles GET /api/messages?property_id=1&status=dead
+func GetOutboundMessages(c *gin.Context) {
+	var propertyID uint
+	fmt.Sscanf(c.Query("property_id"), "%d", &propertyID)
+	if propertyID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "property_id is required"})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	messages, err := services.GetOutboundMessages(propertyID, ownerID, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, messages)
+}
+
+// RetryOutboundMessage handles POST /api/messages/:id/retry for dead-lettered messages
+func RetryOutboundMessage(c *gin.Context) {
+	var messageID uint
+	fmt.Sscanf(c.Param("id"), "%d", &messageID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	if err := services.RetryOutboundMessage(messageID, ownerID); err != nil {
+		switch err.Error() {
+		case "message not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "unauthorized: you do not own this property":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Message queued for retry"})
+}
+
+// GetTenantInboundMessages handles GET /api/tenants/:id/inbound-messages (replies received from the tenant)
+func GetTenantInboundMessages(c *gin.Context) {
+	var tenantID uint
+	fmt.Sscanf(c.Param("id"), "%d", &tenantID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	messages, err := services.GetTenantInboundMessages(tenantID, ownerID)
+	if err != nil {
+		switch err.Error() {
+		case "tenant not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, messages)
+}
+
+// UpdateTenantNotificationPrefs handles PUT /api/tenants/:id/notifications
+// {"notify_channel": "sms", "language": "kn", "mail_id": "ravi@example.com"}
+func UpdateTenantNotificationPrefs(c *gin.Context) {
+	var input struct {
+		NotifyChannel string `json:"notify_channel" binding:"required"`
+		Language      string `json:"language"`
+		MailID        string `json:"mail_id"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var tenantID uint
+	fmt.Sscanf(c.Param("id"), "%d", &tenantID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	profile, err := services.UpdateTenantNotificationPrefs(tenantID, ownerID, input.NotifyChannel, input.Language, input.MailID)
+	if err != nil {
+		switch err.Error() {
+		case "tenant not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "unauthorized: you do not own this property":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notify_channel":      profile.NotifyChannel,
+		"language":            profile.Language,
+		"mail_id":             profile.MailID,
+		"whatsapp_opt_out_at": profile.WhatsAppOptOutAt,
+		"sms_opt_out_at":      profile.SMSOptOutAt,
+	})
+}
+
+// GetTenantConsentHistory handles GET /api/tenants/:id/consent (STOP/START audit trail)
+func GetTenantConsentHistory(c *gin.Context) {
+	var tenantID uint
+	fmt.Sscanf(c.Param("id"), "%d", &tenantID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	events, err := services.GetTenantConsentHistory(tenantID, ownerID)
+	if err != nil {
+		switch err.Error() {
+		case "tenant not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, events)
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+	"pg-manager-backend/models"
+	"pg-manager-backend/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetOwnerAlertSettings handles GET /api/owner/alerts
+func GetOwnerAlertSettings(c *gin.Context) {
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	c.JSON(http.StatusOK, services.GetOwnerAlertSettings(ownerID))
+}
+
+// UpdateOwnerAlertSettings handles PUT /api/owner/alerts with the full set of switches
+func UpdateOwnerAlertSettings(c *gin.Context) {
+	var input models.OwnerAlertSettings
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	settings, err := services.UpdateOwnerAlertSettings(ownerID, input)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
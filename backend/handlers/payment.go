@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"pg-manager-backend/services"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -14,8 +15,9 @@ func RecordPayment(c *gin.Context) {
 
 	// 2. Define struct to match the Frontend JSON exactly
 	var input struct {
-		Amount float64 `json:"amount" binding:"required"`
-		Method string  `json:"method" binding:"required"`
+		Amount     float64 `json:"amount" binding:"required"`
+		Method     string  `json:"method" binding:"required"`
+		InvoiceIDs []uint  `json:"invoice_ids"` // Optional: settle these first instead of the oldest
 	}
 
 	// Bind JSON Input
@@ -29,7 +31,7 @@ func RecordPayment(c *gin.Context) {
 	fmt.Sscanf(userIDStr, "%d", &userID)
 
 	// 3. Call Service Layer
-	newBalance, err := services.RecordManualPayment(userID, input.Amount, input.Method)
+	newBalance, err := services.RecordManualPayment(userID, input.Amount, input.Method, input.InvoiceIDs)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -41,14 +43,132 @@ func RecordPayment(c *gin.Context) {
 	})
 }
 
+// GetPaymentHistory handles GET /api/payments/history
+// Query: property_id, tenant_id, from, to (YYYY-MM-DD), method, type, cursor, limit
 func GetPaymentHistory(c *gin.Context) {
-	// Call the service logic
-	results, err := services.GetAllPaymentHistory()
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
 
+	filter := services.PaymentHistoryFilter{
+		Method:      c.Query("method"),
+		PaymentType: c.Query("type"),
+		Cursor:      c.Query("cursor"),
+	}
+	fmt.Sscanf(c.Query("property_id"), "%d", &filter.PropertyID)
+	fmt.Sscanf(c.Query("tenant_id"), "%d", &filter.TenantID)
+	fmt.Sscanf(c.Query("limit"), "%d", &filter.Limit)
+
+	for param, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Dates must be in YYYY-MM-DD format"})
+				return
+			}
+			*target = &parsed
+		}
+	}
+
+	page, err := services.GetPaymentHistory(ownerID, filter)
 	if err != nil {
+		if err.Error() == "invalid cursor" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch history"})
 		return
 	}
 
-	c.JSON(http.StatusOK, results)
+	c.JSON(http.StatusOK, page)
+}
+
+// ReversePayment handles POST /api/payments/:id/reverse
+func ReversePayment(c *gin.Context) {
+	var paymentID uint
+	fmt.Sscanf(c.Param("id"), "%d", &paymentID)
+
+	var input struct {
+		Reason string `json:"reason" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required to reverse a payment"})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	reversal, newBalance, err := services.ReversePayment(ownerID, paymentID, input.Reason)
+	if err != nil {
+		respondAdjustmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Payment reversed successfully",
+		"reversal":    reversal,
+		"new_balance": newBalance,
+	})
+}
+
+// RefundTenant handles POST /api/tenants/:id/refund
+func RefundTenant(c *gin.Context) {
+	var tenantID uint
+	fmt.Sscanf(c.Param("id"), "%d", &tenantID)
+
+	var input struct {
+		Amount    float64 `json:"amount" binding:"required"`
+		Method    string  `json:"method" binding:"required"`
+		Kind      string  `json:"kind"` // "overpayment" (default) or "deposit"
+		Reason    string  `json:"reason" binding:"required"`
+		PaymentID *uint   `json:"payment_id"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Amount, method and reason are required"})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	refund, newBalance, err := services.RefundTenant(ownerID, tenantID, input.Amount, input.Method, input.Kind, input.Reason, input.PaymentID)
+	if err != nil {
+		respondAdjustmentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Refund recorded successfully",
+		"refund":      refund,
+		"new_balance": newBalance,
+	})
+}
+
+// respondAdjustmentError maps reversal/refund failures to status codes
+func respondAdjustmentError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "unauthorized: you do not own this property":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case "payment not found", "tenant profile not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "payment is already reversed":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// VerifyReceipt handles GET /api/receipts/:id/verify?sig= (public; opened from the receipt's QR code)
+func VerifyReceipt(c *gin.Context) {
+	var paymentID uint
+	fmt.Sscanf(c.Param("id"), "%d", &paymentID)
+
+	verification, err := services.VerifyReceipt(paymentID, c.Query("sig"))
+	if err != nil {
+		// Same answer for a bad signature and a missing receipt, so IDs cannot be probed
+		c.JSON(http.StatusNotFound, gin.H{"valid": false, "error": "Receipt could not be verified"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"valid": verification.Status == "completed", "receipt": verification})
 }
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 	"pg-manager-backend/services"
 
@@ -89,3 +90,217 @@ func GetProperties(c *gin.Context) {
 	}
 	c.JSON(http.StatusOK, properties)
 }
+
+// UpdatePropertyUPI handles PUT /api/properties/:id/upi
+func UpdatePropertyUPI(c *gin.Context) {
+	var input struct {
+		UPIVPA       string `json:"upi_vpa"`
+		UPIPayeeName string `json:"upi_payee_name"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var propertyID uint
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	if err := services.UpdatePropertyUPI(propertyID, ownerID, input.UPIVPA, input.UPIPayeeName); err != nil {
+		if err.Error() == "unauthorized: you do not own this property" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "UPI details updated successfully"})
+}
+
+// UpdatePropertyBranding handles PUT /api/properties/:id/branding
+func UpdatePropertyBranding(c *gin.Context) {
+	var input struct {
+		Address      string `json:"address"`
+		ContactPhone string `json:"contact_phone"`
+		ContactEmail string `json:"contact_email"`
+		GSTIN        string `json:"gstin"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var propertyID uint
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	if err := services.UpdatePropertyBranding(propertyID, ownerID, input.Address, input.ContactPhone, input.ContactEmail, input.GSTIN); err != nil {
+		if err.Error() == "unauthorized: you do not own this property" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Branding updated successfully"})
+}
+
+// UploadPropertyLogo handles POST /api/properties/:id/logo (multipart: file)
+func UploadPropertyLogo(c *gin.Context) {
+	var propertyID uint
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Logo file is required"})
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Could not read uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Could not read uploaded file"})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	if err := services.UploadPropertyLogo(propertyID, ownerID, data); err != nil {
+		if err.Error() == "unauthorized: you do not own this property" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logo uploaded successfully"})
+}
+
+// UpdateNumberingPrefixes handles PUT /api/properties/:id/numbering {"receipt_prefix": "SRI", "invoice_prefix": "SRI/INV"}
+func UpdateNumberingPrefixes(c *gin.Context) {
+	var input struct {
+		ReceiptPrefix string `json:"receipt_prefix"`
+		InvoicePrefix string `json:"invoice_prefix"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var propertyID uint
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	if err := services.UpdateNumberingPrefixes(propertyID, ownerID, input.ReceiptPrefix, input.InvoicePrefix); err != nil {
+		if err.Error() == "unauthorized: you do not own this property" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Numbering prefixes updated successfully"})
+}
+
+// UpdateReminderSettings handles PUT /api/properties/:id/reminders
+// {"offsets": [-3, 0, 3, 7], "quiet_hours_start": "21:00", "quiet_hours_end": "08:00"}
+func UpdateReminderSettings(c *gin.Context) {
+	var input struct {
+		Offsets         []int  `json:"offsets" binding:"required"`
+		QuietHoursStart string `json:"quiet_hours_start" binding:"required"`
+		QuietHoursEnd   string `json:"quiet_hours_end" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var propertyID uint
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	if err := services.UpdateReminderSettings(propertyID, ownerID, input.Offsets, input.QuietHoursStart, input.QuietHoursEnd); err != nil {
+		if err.Error() == "unauthorized: you do not own this property" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Reminder schedule updated successfully"})
+}
+
+// GetOverdueBuckets handles GET /api/properties/:id/overdue
+func GetOverdueBuckets(c *gin.Context) {
+	var propertyID uint
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	buckets, err := services.GetOverdueBuckets(propertyID, ownerID)
+	if err != nil {
+		if err.Error() == "unauthorized: you do not own this property" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, buckets)
+}
+
+// UpdateLateFeeSettings handles PUT /api/properties/:id/late-fee {"amount": 200, "grace_days": 5}
+func UpdateLateFeeSettings(c *gin.Context) {
+	var input struct {
+		Amount    float64 `json:"amount"`
+		GraceDays int     `json:"grace_days"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var propertyID uint
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	if err := services.UpdateLateFeeSettings(propertyID, ownerID, input.Amount, input.GraceDays); err != nil {
+		if err.Error() == "unauthorized: you do not own this property" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Late fee settings updated successfully"})
+}
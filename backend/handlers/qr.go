@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"pg-manager-backend/services"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetPublicComplaintForm handles GET /api/public/complaint/:token — property name and room for the QR form
+func GetPublicComplaintForm(c *gin.Context) {
+	form, err := services.GetPublicForm(c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "This QR code is no longer valid. Please ask your PG owner for the current one."})
+		return
+	}
+
+	c.JSON(http.StatusOK, form)
+}
+
+// DownloadQRPoster handles GET /api/properties/:id/qr-poster?format=pdf|png&room_id=&rooms=all
+func DownloadQRPoster(c *gin.Context) {
+	var propertyID, roomID uint
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+	fmt.Sscanf(c.Query("room_id"), "%d", &roomID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	poster, err := services.GenerateQRPosters(propertyID, ownerID, roomID, c.Query("rooms") == "all", c.DefaultQuery("format", "pdf"))
+	if err != nil {
+		switch {
+		case err.Error() == "unauthorized: you do not own this property":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case err.Error() == "room not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case strings.HasPrefix(err.Error(), "invalid"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not generate poster"})
+		}
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="`+poster.FileName+`"`)
+	c.Data(http.StatusOK, poster.ContentType, poster.Data)
+}
+
+// RotateQRCodes handles POST /api/properties/:id/qr-poster/rotate; every printed poster stops working
+func RotateQRCodes(c *gin.Context) {
+	var propertyID uint
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	if err := services.RotateQRCodes(propertyID, ownerID); err != nil {
+		if err.Error() == "unauthorized: you do not own this property" {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "QR codes rotated. Print and put up the new posters."})
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"pg-manager-backend/services"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondDocumentError maps receipt/statement/export failures to status codes
+func respondDocumentError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "unauthorized: you do not own this property":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err.Error() == "payment not found", err.Error() == "tenant profile not found", err.Error() == "no payments in this month":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "invalid"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// RegenerateReceipt handles POST /api/payments/:id/receipt
+func RegenerateReceipt(c *gin.Context) {
+	var paymentID uint
+	fmt.Sscanf(c.Param("id"), "%d", &paymentID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	receipt, err := services.RegenerateReceipt(paymentID, ownerID)
+	if err != nil {
+		respondDocumentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, receipt)
+}
+
+// GetTenantStatement handles GET /api/tenants/:id/statement?from=YYYY-MM-DD&to=YYYY-MM-DD
+// Without dates it covers the last three months.
+func GetTenantStatement(c *gin.Context) {
+	var tenantID uint
+	fmt.Sscanf(c.Param("id"), "%d", &tenantID)
+
+	to := time.Now()
+	from := to.AddDate(0, -3, 0)
+	for param, target := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := c.Query(param); value != "" {
+			parsed, err := time.ParseInLocation("2006-01-02", value, time.Local)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Dates must be in YYYY-MM-DD format"})
+				return
+			}
+			*target = parsed
+		}
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	pdf, err := services.GenerateTenantStatement(tenantID, ownerID, from, to)
+	if err != nil {
+		respondDocumentError(c, err)
+		return
+	}
+
+	fileName := fmt.Sprintf("statement_%d_%s_%s.pdf", tenantID, from.Format("20060102"), to.Format("20060102"))
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// ExportPropertyReceipts handles GET /api/properties/:id/receipts/export?month=YYYY-MM
+func ExportPropertyReceipts(c *gin.Context) {
+	var propertyID uint
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	month := c.Query("month")
+	archive, err := services.ExportPropertyReceipts(propertyID, ownerID, month)
+	if err != nil {
+		respondDocumentError(c, err)
+		return
+	}
+
+	fileName := fmt.Sprintf("receipts_%d_%s.zip", propertyID, month)
+	c.Header("Content-Disposition", `attachment; filename="`+fileName+`"`)
+	c.Data(http.StatusOK, "application/zip", archive)
+}
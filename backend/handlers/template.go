@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"pg-manager-backend/models"
+	"pg-manager-backend/services"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMessageTemplates handles GET /api/properties/:id/templates
+func GetMessageTemplates(c *gin.Context) {
+	var propertyID uint
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	templates, err := services.GetMessageTemplates(propertyID, ownerID)
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+// SaveMessageTemplate handles PUT /api/properties/:id/templates/:name/:locale; each save is a new version
+func SaveMessageTemplate(c *gin.Context) {
+	var propertyID uint
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+
+	var input models.MessageTemplate
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
+		return
+	}
+	input.Name = c.Param("name")
+	input.Locale = c.Param("locale")
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	template, err := services.SaveMessageTemplate(propertyID, ownerID, input)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// GetMessageTemplateVersions handles GET /api/properties/:id/templates/:name/:locale/versions
+func GetMessageTemplateVersions(c *gin.Context) {
+	var propertyID uint
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	versions, err := services.GetMessageTemplateVersions(propertyID, ownerID, c.Param("name"), c.Param("locale"))
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// RestoreMessageTemplateVersion handles POST /api/properties/:id/templates/:name/:locale/versions/:version/restore
+func RestoreMessageTemplateVersion(c *gin.Context) {
+	var propertyID uint
+	var version int
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+	fmt.Sscanf(c.Param("version"), "%d", &version)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	template, err := services.RestoreMessageTemplateVersion(propertyID, ownerID, c.Param("name"), c.Param("locale"), version)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+// ResetMessageTemplate handles DELETE /api/properties/:id/templates/:name/:locale (back to the built-in default)
+func ResetMessageTemplate(c *gin.Context) {
+	var propertyID uint
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	if err := services.ResetMessageTemplate(propertyID, ownerID, c.Param("name"), c.Param("locale")); err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Template reset to default"})
+}
+
+// PreviewMessageTemplate handles POST /api/properties/:id/templates/:name/:locale/preview
+// An empty body previews the template currently in use.
+func PreviewMessageTemplate(c *gin.Context) {
+	var propertyID uint
+	fmt.Sscanf(c.Param("id"), "%d", &propertyID)
+
+	var draft models.MessageTemplate
+	c.ShouldBindJSON(&draft)
+	draft.Name = c.Param("name")
+	draft.Locale = c.Param("locale")
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	rendered, err := services.PreviewMessageTemplate(propertyID, ownerID, draft)
+	if err != nil {
+		respondTemplateError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rendered)
+}
+
+func respondTemplateError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "unauthorized: you do not own this property":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err.Error() == "unknown template", err.Error() == "template version not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "invalid template"), err.Error() == "template body is required",
+		strings.HasPrefix(err.Error(), "unsupported locale"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"pg-manager-backend/models"
 	"pg-manager-backend/services"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -18,6 +19,10 @@ func OnboardTenant(c *gin.Context) {
 
 	tenantID, err := services.OnboardTenant(input)
 	if err != nil {
+		if strings.HasPrefix(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		// Professional tip: Use a status code that reflects the error (like Conflict if room is full)
 		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		return
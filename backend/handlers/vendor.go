@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"pg-manager-backend/services"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// respondWorkOrderError maps vendor and work order failures to status codes
+func respondWorkOrderError(c *gin.Context, err error) {
+	switch {
+	case err.Error() == "unauthorized: you do not own this property":
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case strings.HasSuffix(err.Error(), "not found"):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "invalid"):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// CreateVendor handles POST /api/vendors
+func CreateVendor(c *gin.Context) {
+	var input services.VendorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	vendor, err := services.CreateVendor(ownerID, input)
+	if err != nil {
+		respondWorkOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, vendor)
+}
+
+// UpdateVendor handles PUT /api/vendors/:id
+func UpdateVendor(c *gin.Context) {
+	var vendorID uint
+	fmt.Sscanf(c.Param("id"), "%d", &vendorID)
+
+	var input services.VendorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	vendor, err := services.UpdateVendor(vendorID, ownerID, input)
+	if err != nil {
+		respondWorkOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, vendor)
+}
+
+// GetVendors handles GET /api/vendors?trade=Plumbing&active=true
+func GetVendors(c *gin.Context) {
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	vendors, err := services.GetVendors(ownerID, c.Query("trade"), c.Query("active") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not fetch vendors"})
+		return
+	}
+
+	c.JSON(http.StatusOK, vendors)
+}
+
+// CreateWorkOrder handles POST /api/work-orders {vendor_id, complaint_id | property_id, title, description}
+func CreateWorkOrder(c *gin.Context) {
+	var input services.WorkOrderInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	order, err := services.CreateWorkOrder(ownerID, input)
+	if err != nil {
+		respondWorkOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, order)
+}
+
+// GetWorkOrders handles GET /api/work-orders?property_id=&status=&complaint_id=
+func GetWorkOrders(c *gin.Context) {
+	var propertyID, complaintID uint
+	fmt.Sscanf(c.Query("property_id"), "%d", &propertyID)
+	fmt.Sscanf(c.Query("complaint_id"), "%d", &complaintID)
+	if propertyID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Property ID is required"})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	orders, err := services.GetWorkOrders(propertyID, ownerID, c.Query("status"), complaintID)
+	if err != nil {
+		respondWorkOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, orders)
+}
+
+// QuoteWorkOrder handles PUT /api/work-orders/:id/quote {amount, note}
+func QuoteWorkOrder(c *gin.Context) {
+	var workOrderID uint
+	fmt.Sscanf(c.Param("id"), "%d", &workOrderID)
+
+	var input struct {
+		Amount float64 `json:"amount" binding:"required"`
+		Note   string  `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	order, err := services.RecordWorkOrderQuote(workOrderID, ownerID, input.Amount, input.Note)
+	if err != nil {
+		respondWorkOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// ApproveWorkOrder handles PUT /api/work-orders/:id/approve
+func ApproveWorkOrder(c *gin.Context) {
+	var workOrderID uint
+	fmt.Sscanf(c.Param("id"), "%d", &workOrderID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	order, err := services.ApproveWorkOrder(workOrderID, ownerID)
+	if err != nil {
+		respondWorkOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// CompleteWorkOrder handles PUT /api/work-orders/:id/complete {final_amount, note, resolve_complaint}
+func CompleteWorkOrder(c *gin.Context) {
+	var workOrderID uint
+	fmt.Sscanf(c.Param("id"), "%d", &workOrderID)
+
+	var input services.WorkOrderCompletion
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	order, err := services.CompleteWorkOrder(workOrderID, ownerID, input)
+	if err != nil {
+		respondWorkOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// CancelWorkOrder handles PUT /api/work-orders/:id/cancel
+func CancelWorkOrder(c *gin.Context) {
+	var workOrderID uint
+	fmt.Sscanf(c.Param("id"), "%d", &workOrderID)
+
+	ownerIDFloat, _ := c.Get("user_id")
+	ownerID := uint(ownerIDFloat.(float64))
+
+	order, err := services.CancelWorkOrder(workOrderID, ownerID)
+	if err != nil {
+		respondWorkOrderError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
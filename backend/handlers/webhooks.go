@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"pg-manager-backend/services"
 
@@ -30,3 +31,45 @@ func RazorpayWebhook(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"status": "payment_processed_successfully"})
 }
+
+// TwilioStatusCallback handles POST /api/webhooks/twilio/status (behind TwilioSignatureMiddleware)
+func TwilioStatusCallback(c *gin.Context) {
+	sid := c.PostForm("MessageSid")
+	status := c.PostForm("MessageStatus")
+	if sid == "" || status == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "MessageSid and MessageStatus are required"})
+		return
+	}
+
+	if err := services.HandleTwilioStatus(sid, status, c.PostForm("ErrorCode"), c.PostForm("ErrorMessage")); err != nil {
+		// Acknowledge anyway: callbacks for messages sent outside the outbox are expected
+		c.JSON(http.StatusOK, gin.H{"status": "ignored", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "updated"})
+}
+
+// TwilioInboundMessage handles POST /api/webhooks/twilio/inbound (behind TwilioSignatureMiddleware)
+func TwilioInboundMessage(c *gin.Context) {
+	var mediaURLs []string
+	var numMedia int
+	fmt.Sscanf(c.PostForm("NumMedia"), "%d", &numMedia)
+	for i := 0; i < numMedia; i++ {
+		mediaURLs = append(mediaURLs, c.PostForm(fmt.Sprintf("MediaUrl%d", i)))
+	}
+
+	inbound, profile, err := services.HandleInboundMessage(c.PostForm("MessageSid"), c.PostForm("From"), c.PostForm("To"), c.PostForm("Body"), mediaURLs)
+	if err != nil {
+		if err.Error() == "duplicate message" {
+			c.Data(http.StatusOK, "text/xml", []byte("<Response></Response>"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The chatbot replies through the outbox, so the TwiML response stays empty
+	services.HandleChatbotMessage(inbound, profile)
+	c.Data(http.StatusOK, "text/xml", []byte("<Response></Response>"))
+}
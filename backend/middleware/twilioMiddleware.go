@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+	"pg-manager-backend/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/twilio/twilio-go/client"
+)
+
+// TwilioSignatureMiddleware rejects webhook calls that are not signed with our Twilio auth token.
+// Twilio signs the public URL it called, so BASE_URL must match what is configured in the console.
+func TwilioSignatureMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if config.App.TwilioAuthToken == "" {
+			if config.App.Environment == "production" {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Twilio is not configured"})
+				c.Abort()
+				return
+			}
+			log.Println("⚠️ TWILIO_AUTH_TOKEN not set; skipping webhook signature check (development only)")
+			c.Next()
+			return
+		}
+
+		if err := c.Request.ParseForm(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form payload"})
+			c.Abort()
+			return
+		}
+
+		params := make(map[string]string, len(c.Request.PostForm))
+		for key, values := range c.Request.PostForm {
+			if len(values) > 0 {
+				params[key] = values[0]
+			}
+		}
+
+		validator := client.NewRequestValidator(config.App.TwilioAuthToken)
+		url := config.App.BaseURL + c.Request.URL.RequestURI()
+		if !validator.Validate(url, params, c.GetHeader("X-Twilio-Signature")) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid Twilio signature"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -26,6 +26,32 @@ type Property struct {
 	Address   string         `json:"address"`
 	OwnerID   uint           `json:"owner_id"`
 	Rooms     []Room         `json:"rooms" gorm:"foreignKey:PropertyID"`
+
+	// Letterhead printed on receipts and invoices
+	ContactPhone string `json:"contact_phone"`
+	ContactEmail string `json:"contact_email"`
+	GSTIN        string `json:"gstin"`     // Printed only when configured
+	LogoPath     string `json:"logo_path"` // Storage key of the PNG/JPEG uploaded via /properties/:id/logo
+
+	// Document numbering, e.g. "SRI" → SRI/24-25/000123; empty means derived from the name
+	ReceiptPrefix string `json:"receipt_prefix"`
+	InvoicePrefix string `json:"invoice_prefix"`
+
+	// UPI collection details printed on invoices and payment reminders
+	UPIVPA       string `json:"upi_vpa"`
+	UPIPayeeName string `json:"upi_payee_name"`
+
+	// Rent reminders: days relative to the due date (negative = before) and a window with no messages
+	ReminderOffsets string `json:"reminder_offsets" gorm:"default:-3,0,3,7"`
+	QuietHoursStart string `json:"quiet_hours_start" gorm:"default:21:00"` // "HH:MM"; equal start/end disables
+	QuietHoursEnd   string `json:"quiet_hours_end" gorm:"default:08:00"`
+
+	// Late fee raised once per invoice still unpaid GraceDays after its due date; 0 disables
+	LateFeeAmount    float64 `json:"late_fee_amount"`
+	LateFeeGraceDays int     `json:"late_fee_grace_days" gorm:"default:5"`
+
+	// Bumped to invalidate every printed QR code of the property at once
+	QRVersion int `json:"qr_version"`
 }
 
 // Room represents an individual room
@@ -33,6 +59,7 @@ type Room struct {
 	gorm.Model
 	PropertyID uint    `json:"property_id"`
 	RoomNumber string  `json:"room_no" gorm:"column:room_no"`
+	Floor      string  `json:"floor"` // Optional; derived from the room number ("204" → "2") when empty
 	Capacity   int     `json:"capacity"`
 	Price      float64 `json:"price"`   // Rent per bed
 	Deposit    float64 `json:"deposit"` // FIXED DEPOSIT for the room
@@ -61,6 +88,14 @@ type TenantProfile struct {
 	EmergencyRelation string `json:"emergency_relation"`
 	MailID            string `json:"mail_id"`
 
+	// Channel used for bills, receipts and alerts: "whatsapp", "sms" or "email"
+	NotifyChannel string `json:"notify_channel" gorm:"default:whatsapp"`
+	Language      string `json:"language" gorm:"default:en"` // "en", "kn" or "hi"
+
+	// Set when the tenant replies STOP on that channel; only a START from the tenant clears it
+	WhatsAppOptOutAt *time.Time `json:"whatsapp_opt_out_at" gorm:"column:whatsapp_opt_out_at"`
+	SMSOptOutAt      *time.Time `json:"sms_opt_out_at"`
+
 	// Preferences & Logistics
 	IsVegetarian  bool   `json:"is_vegetarian"`
 	HasTwoWheeler bool   `json:"has_two_wheeler"`
@@ -94,12 +129,67 @@ type TenantProfile struct {
 type Complaint struct {
 	ID          uint      `gorm:"primaryKey" json:"id"`
 	PropertyID  uint      `json:"property_id"`
-	RoomNo      string    `json:"room_no"`
+	TenantID    uint      `json:"tenant_id" gorm:"index"` // users.id of the tenant who raised it
+	RoomID      uint      `json:"room_id" gorm:"index"`
+	RoomNo      string    `json:"room_no"` // Room and name as they were when raised
 	TenantName  string    `json:"tenant_name"`
 	PhoneNumber string    `json:"phone_number"`
 	Category    string    `json:"category"`
 	Description string    `json:"description"`
-	Status      string    `json:"status" gorm:"default:'Pending'"`
+	Status      string    `json:"status" gorm:"default:open;index"` // open, acknowledged, in_progress, on_hold, resolved, reopened, closed
+	Priority    string    `json:"priority" gorm:"default:medium"`   // low, medium, high, urgent
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Who is fixing it: a staff member or an outside vendor
+	AssigneeType  string `json:"assignee_type"` // "staff" or "vendor"
+	AssigneeName  string `json:"assignee_name"`
+	AssigneePhone string `json:"assignee_phone"`
+
+	// SLA: DueAt comes from the property's resolution hours for the category
+	DueAt          *time.Time `json:"due_at" gorm:"index"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+	ClosedAt       *time.Time `json:"closed_at"`
+
+	// Tenant feedback on the latest resolution; cleared if the tenant reopens
+	Rating        *int       `json:"rating"` // 1–5 stars
+	RatingComment string     `json:"rating_comment"`
+	RatedAt       *time.Time `json:"rated_at"`
+}
+
+// ComplaintEvent is one entry in a complaint's history: a status change, assignment, priority change or comment
+type ComplaintEvent struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ComplaintID uint      `json:"complaint_id" gorm:"index"`
+	Action      string    `json:"action"` // "created", "status", "assigned", "priority" or "comment"
+	FromStatus  string    `json:"from_status"`
+	ToStatus    string    `json:"to_status"`
+	Comment     string    `json:"comment" gorm:"type:text"`
+	ActorID     uint      `json:"actor_id"` // users.id; 0 for the tenant form or the system
+	ActorName   string    `json:"actor_name"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ComplaintSLA overrides the default resolution time for one category at a property
+type ComplaintSLA struct {
+	ID           uint   `gorm:"primaryKey" json:"id"`
+	PropertyID   uint   `json:"property_id" gorm:"uniqueIndex:idx_complaint_sla"`
+	Category     string `json:"category" gorm:"uniqueIndex:idx_complaint_sla"`
+	ResolveHours int    `json:"resolve_hours"`
+}
+
+// ComplaintAttachment is a photo or video on a complaint: "before" from the tenant, "after" as proof of the fix
+type ComplaintAttachment struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ComplaintID uint      `json:"complaint_id" gorm:"index"`
+	Stage       string    `json:"stage"`      // "before" or "after"
+	MediaType   string    `json:"media_type"` // "image" or "video"
+	ContentType string    `json:"content_type"`
+	FileKey     string    `json:"file_key"`
+	ThumbKey    string    `json:"thumb_key"` // Empty for videos and images that could not be decoded
+	Size        int64     `json:"size"`
+	UploadedBy  uint      `json:"uploaded_by"` // users.id; the tenant for "before" media
 	CreatedAt   time.Time `json:"created_at"`
 }
 
@@ -111,6 +201,49 @@ type Expenditure struct {
 	Category    string    `json:"category" binding:"required"` // Can be "Electricity" or "New Lightbulbs"
 	Description string    `json:"description"`
 	Date        time.Time `json:"date"`
+
+	// Set when the cost belongs to a repair, so owners can see what each issue cost
+	ComplaintID *uint `json:"complaint_id" gorm:"index"`
+	WorkOrderID *uint `json:"work_order_id" gorm:"index"`
+}
+
+// Vendor is an outside tradesperson in the owner's directory, shared across their properties
+type Vendor struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	OwnerID   uint      `json:"owner_id" gorm:"index"`
+	Name      string    `json:"name"`
+	Phone     string    `json:"phone"`
+	Email     string    `json:"email"`
+	Trades    string    `json:"trades"` // Complaint categories they handle, comma-separated: "Plumbing,Electrical"
+	Notes     string    `json:"notes"`
+	Active    bool      `json:"active" gorm:"default:true"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WorkOrder is a job given to a vendor, usually for a complaint: requested → quoted → approved → completed
+type WorkOrder struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	PropertyID  uint      `json:"property_id" gorm:"index"`
+	ComplaintID *uint     `json:"complaint_id" gorm:"index"`
+	VendorID    uint      `json:"vendor_id" gorm:"index"`
+	Vendor      Vendor    `json:"vendor" gorm:"foreignKey:VendorID"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Status      string    `json:"status" gorm:"default:requested;index"` // requested, quoted, approved, completed, cancelled
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	QuoteAmount *float64   `json:"quote_amount"`
+	QuoteNote   string     `json:"quote_note"`
+	QuotedAt    *time.Time `json:"quoted_at"`
+	ApprovedAt  *time.Time `json:"approved_at"`
+
+	// Completion books the final amount as an Expenditure
+	FinalAmount    float64    `json:"final_amount"`
+	CompletionNote string     `json:"completion_note"`
+	CompletedAt    *time.Time `json:"completed_at"`
+	ExpenditureID  *uint      `json:"expenditure_id"`
 }
 
 type Payment struct {
@@ -118,10 +251,43 @@ type Payment struct {
 	PropertyID  uint      `json:"property_id"`
 	TenantID    uint      `json:"tenant_id"`
 	Amount      float64   `json:"amount"`
-	PaymentType string    `json:"payment_type"` // e.g., "Rent", "Deposit", "Maintenance"
-	Method      string    `json:"method"`       // e.g., "Cash", "UPI", "Bank Transfer"
+	PaymentType string    `json:"payment_type"`            // e.g., "Rent", "Deposit", "Maintenance"
+	Method      string    `json:"method"`                  // e.g., "Cash", "UPI", "Bank Transfer"
+	Reference   string    `json:"reference" gorm:"index"`  // UTR / gateway reference, if any
+	ReceiptNo   string    `json:"receipt_no" gorm:"index"` // Per-property, per-financial-year series; empty on older rows
+	InvoiceID   *uint     `json:"invoice_id"`              // Invoice the payer was settling, if known
 	Date        time.Time `json:"date"`
 	CreatedAt   time.Time `json:"created_at"`
+
+	BalanceAfter *float64 `json:"balance_after"` // Tenant balance right after this entry; nil for older rows
+
+	// Corrections are new rows with a negative amount; the original is only flagged, never deleted
+	Status     string     `json:"status" gorm:"default:completed"` // "completed" or "reversed"
+	ReversalOf *uint      `json:"reversal_of" gorm:"index"`        // Payment this reversal/refund offsets
+	Reason     string     `json:"reason"`
+	RecordedBy uint       `json:"recorded_by"`
+	ReversedAt *time.Time `json:"reversed_at"`
+
+	Unallocated float64 `json:"unallocated"` // Part not yet applied to any invoice, i.e. carry-forward credit
+}
+
+// Invoice is a single charge raised against a tenant (monthly rent, admission dues)
+type Invoice struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	PropertyID  uint       `json:"property_id" gorm:"index"`
+	TenantID    uint       `json:"tenant_id" gorm:"index"`       // users.id, same as Payment.TenantID
+	Reference   string     `json:"reference" gorm:"uniqueIndex"` // Short UPI "tr" reference
+	Number      string     `json:"number" gorm:"index"`          // Series number printed on the invoice, e.g. SRI/INV/24-25/000045
+	Description string     `json:"description"`
+	Amount      float64    `json:"amount"`
+	AmountPaid  float64    `json:"amount_paid"`
+	Status      string     `json:"status" gorm:"default:open;index"` // "open", "partial" or "paid"
+	DueDate     time.Time  `json:"due_date"`
+	PaidAt      *time.Time `json:"paid_at"`
+	LateFeeFor  *uint      `json:"late_fee_for" gorm:"index"` // Set on late fee invoices: the overdue invoice they penalise
+	CreatedAt   time.Time  `json:"created_at"`
+
+	OwnerAlertedAt *time.Time `json:"owner_alerted_at"` // When the owner was told it passed their overdue threshold
 }
 
 type ArchivedTenant struct {
@@ -138,3 +304,204 @@ type ArchivedTenant struct {
 	AdmissionDate    time.Time `json:"admission_date"`
 	CheckoutDate     time.Time `json:"checkout_date"`
 }
+
+// PaymentAllocation records how much of a payment went to which invoice.
+// Reversals add negative rows rather than deleting the originals.
+type PaymentAllocation struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	PaymentID uint      `json:"payment_id" gorm:"index"`
+	InvoiceID uint      `json:"invoice_id" gorm:"index"`
+	Invoice   Invoice   `json:"invoice" gorm:"foreignKey:InvoiceID"`
+	TenantID  uint      `json:"tenant_id" gorm:"index"`
+	Amount    float64   `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OutboundMessage is a queued tenant/owner notification, sent by the outbox workers with retries
+type OutboundMessage struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	PropertyID    uint       `json:"property_id" gorm:"index"`
+	TenantID      uint       `json:"tenant_id" gorm:"index"` // users.id; 0 for owner messages
+	Kind          string     `json:"kind"`                   // e.g. "payment_received", "rent_due"
+	Channel       string     `json:"channel"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Body          string     `json:"body" gorm:"type:text"`
+	Status        string     `json:"status" gorm:"default:pending;index"` // pending, sending, sent, dead
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" gorm:"index"`
+	LastError     string     `json:"last_error"`
+	TemplateSID   string     `json:"template_sid"`                   // WhatsApp pre-approved template (Twilio Content SID)
+	TemplateVars  string     `json:"template_vars" gorm:"type:text"` // JSON {"1": "...", "2": "..."}
+	ProviderID    string     `json:"provider_id" gorm:"index"`       // Twilio SID / SMTP Message-ID
+	SentAt        *time.Time `json:"sent_at"`
+
+	FallbackOf *uint `json:"fallback_of" gorm:"index"` // Message on another channel that failed before this one
+
+	// Delivery receipts from the provider's status callback
+	DeliveryStatus string     `json:"delivery_status"` // queued, sent, delivered, read, failed, undelivered
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	ReadAt         *time.Time `json:"read_at"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// InboundMessage is a WhatsApp/SMS message a tenant sent to the PG number
+type InboundMessage struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	PropertyID uint      `json:"property_id" gorm:"index"` // 0 when the sender is not a known tenant
+	TenantID   uint      `json:"tenant_id" gorm:"index"`   // users.id
+	Channel    string    `json:"channel"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Body       string    `json:"body" gorm:"type:text"`
+	MediaURLs  string    `json:"media_urls" gorm:"type:text"`    // Newline-separated
+	ProviderID string    `json:"provider_id" gorm:"uniqueIndex"` // Twilio MessageSid; guards against webhook retries
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// RentReminder records that a tenant was reminded for a due date at a given offset, so it is never sent twice
+type RentReminder struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	PropertyID uint      `json:"property_id" gorm:"index"`
+	TenantID   uint      `json:"tenant_id" gorm:"uniqueIndex:idx_reminder_upcoming,where:invoice_id IS NULL"`     // users.id
+	InvoiceID  *uint     `json:"invoice_id" gorm:"uniqueIndex:idx_reminder_invoice,where:invoice_id IS NOT NULL"` // Nil for reminders before the invoice is raised
+	DueDate    time.Time `json:"due_date" gorm:"type:date;uniqueIndex:idx_reminder_upcoming"`
+	OffsetDays int       `json:"offset_days" gorm:"uniqueIndex:idx_reminder_upcoming;uniqueIndex:idx_reminder_invoice"`
+	SentAt     time.Time `json:"sent_at"`
+}
+
+// OwnerAlertSettings controls which events an owner is told about and where
+type OwnerAlertSettings struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	OwnerID         uint      `json:"owner_id" gorm:"uniqueIndex"`
+	Channel         string    `json:"channel" gorm:"default:whatsapp"` // "whatsapp" or "email"
+	NewComplaint    bool      `json:"new_complaint" gorm:"default:true"`
+	PaymentReceived bool      `json:"payment_received" gorm:"default:true"` // Online payments; owners record cash themselves
+	PaymentFailed   bool      `json:"payment_failed" gorm:"default:true"`
+	TenantOverdue   bool      `json:"tenant_overdue" gorm:"default:true"`
+	OverdueDays     int       `json:"overdue_days" gorm:"default:7"` // Alert when a tenant's oldest unpaid invoice reaches this age
+	RoomVacated     bool      `json:"room_vacated" gorm:"default:true"`
+	DailyDigest     bool      `json:"daily_digest" gorm:"default:true"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// JobRun is one execution of a scheduled job, kept for the status page
+type JobRun struct {
+	ID           uint       `gorm:"primaryKey" json:"id"`
+	JobName      string     `json:"job_name" gorm:"index"`
+	Trigger      string     `json:"trigger"`                    // "schedule" or "manual"
+	TriggeredBy  uint       `json:"triggered_by"`               // users.id for manual runs
+	OwnerID      uint       `json:"owner_id" gorm:"index"`      // Owner whose properties a manual run covered; 0 = every owner
+	ScheduledFor *time.Time `json:"scheduled_for" gorm:"index"` // Tick this run belongs to; one run per tick across replicas
+	Status       string     `json:"status" gorm:"index"`        // running, succeeded, failed, skipped
+	Error        string     `json:"error" gorm:"type:text"`
+	Host         string     `json:"host"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at"`
+	DurationMs   int64      `json:"duration_ms"`
+}
+
+// NumberSeries is the last number issued for one property, document kind and financial year
+type NumberSeries struct {
+	ID            uint   `gorm:"primaryKey" json:"id"`
+	PropertyID    uint   `json:"property_id" gorm:"uniqueIndex:idx_series"`
+	Kind          string `json:"kind" gorm:"uniqueIndex:idx_series"`           // "receipt" or "invoice"
+	FinancialYear string `json:"financial_year" gorm:"uniqueIndex:idx_series"` // "24-25" (April to March)
+	LastNumber    int    `json:"last_number"`
+}
+
+// Announcement is a one-off message from the owner to some or all tenants of a property
+type Announcement struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	PropertyID     uint      `json:"property_id" gorm:"index"`
+	Title          string    `json:"title"`
+	Body           string    `json:"body" gorm:"type:text"`
+	Target         string    `json:"target"`   // "all", "floor", "rooms" or "dues"
+	Floor          string    `json:"floor"`    // Target "floor"
+	RoomIDs        string    `json:"room_ids"` // Target "rooms": comma-separated room IDs
+	MinDue         float64   `json:"min_due"`  // Target "dues": balance above this amount
+	RecipientCount int       `json:"recipient_count"`
+	CreatedBy      uint      `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// AnnouncementRecipient links an announcement to each tenant and the queued message that reached them
+type AnnouncementRecipient struct {
+	ID                uint   `gorm:"primaryKey" json:"id"`
+	AnnouncementID    uint   `json:"announcement_id" gorm:"index"`
+	TenantID          uint   `json:"tenant_id"` // users.id
+	TenantName        string `json:"tenant_name"`
+	OutboundMessageID *uint  `json:"outbound_message_id"`
+	SkipReason        string `json:"skip_reason"` // Set when nothing was queued (e.g. opted out everywhere)
+}
+
+// ConsentEvent is the audit trail of a tenant's messaging opt-outs and opt-ins
+type ConsentEvent struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	PropertyID  uint      `json:"property_id" gorm:"index"`
+	TenantID    uint      `json:"tenant_id" gorm:"index"` // users.id
+	PhoneNumber string    `json:"phone_number"`
+	Channel     string    `json:"channel"`
+	Action      string    `json:"action"`  // "opt_out" or "opt_in"
+	Keyword     string    `json:"keyword"` // What the tenant sent, e.g. "STOP"
+	InboundID   uint      `json:"inbound_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ChatSession holds a tenant's position in a multi-step chatbot conversation
+type ChatSession struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	TenantID  uint      `json:"tenant_id" gorm:"uniqueIndex"` // users.id
+	State     string    `json:"state"`                        // idle, complaint_description, complaint_category
+	Pending   string    `json:"pending" gorm:"type:text"`     // Text collected so far (e.g. complaint description)
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MessageTemplate is a property's override of a built-in message. Every edit is a new version;
+// the highest active version for (property, name, locale) wins.
+type MessageTemplate struct {
+	ID                 uint      `gorm:"primaryKey" json:"id"`
+	PropertyID         uint      `json:"property_id" gorm:"uniqueIndex:idx_template_version"`
+	Name               string    `json:"name" gorm:"uniqueIndex:idx_template_version"` // rent_due, payment_received, ...
+	Locale             string    `json:"locale" gorm:"uniqueIndex:idx_template_version"`
+	Version            int       `json:"version" gorm:"uniqueIndex:idx_template_version"`
+	Subject            string    `json:"subject"`
+	Body               string    `json:"body" gorm:"type:text"`
+	WhatsAppContentSID string    `json:"whatsapp_content_sid"` // Pre-approved WhatsApp Business template
+	WhatsAppVariables  string    `json:"whatsapp_variables"`   // Comma-separated fields mapped to {{1}}, {{2}}, ...
+	IsActive           bool      `json:"is_active" gorm:"default:true"`
+	CreatedBy          uint      `json:"created_by"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// BankStatement is one uploaded CSV/OFX export from the owner's bank
+type BankStatement struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	PropertyID uint      `json:"property_id" gorm:"index"`
+	UploadedBy uint      `json:"uploaded_by"`
+	FileName   string    `json:"file_name"`
+	Format     string    `json:"format"` // "csv" or "ofx"
+	LineCount  int       `json:"line_count"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// BankStatementLine is a single credit from a statement waiting to be matched to a tenant
+type BankStatementLine struct {
+	ID                uint      `gorm:"primaryKey" json:"id"`
+	StatementID       uint      `json:"statement_id" gorm:"index"`
+	PropertyID        uint      `json:"property_id" gorm:"index"`
+	TxnDate           time.Time `json:"txn_date"`
+	Amount            float64   `json:"amount"`
+	Description       string    `json:"description"`
+	Reference         string    `json:"reference"` // UTR / FITID
+	PayerName         string    `json:"payer_name"`
+	Fingerprint       string    `json:"-" gorm:"uniqueIndex"`                  // Stops the same line being imported twice
+	Status            string    `json:"status" gorm:"default:unmatched;index"` // unmatched, suggested, posted, ignored, duplicate
+	SuggestedTenantID *uint     `json:"suggested_tenant_id"`
+	MatchScore        int       `json:"match_score"`
+	MatchReason       string    `json:"match_reason"`
+	PaymentID         *uint     `json:"payment_id"`
+	CreatedAt         time.Time `json:"created_at"`
+}
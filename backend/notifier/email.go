@@ -0,0 +1,58 @@
+package notifier
+
+import (
+	"fmt"
+	"net/smtp"
+	"pg-manager-backend/config"
+	"strings"
+	"time"
+)
+
+// SMTPNotifier sends plain-text email through the configured SMTP relay
+type SMTPNotifier struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPEmail builds an SMTP notifier from SMTP_* settings
+func NewSMTPEmail() *SMTPNotifier {
+	var auth smtp.Auth
+	if config.App.SMTPUser != "" {
+		auth = smtp.PlainAuth("", config.App.SMTPUser, config.App.SMTPPass, config.App.SMTPHost)
+	}
+
+	from := config.App.SMTPFrom
+	if from == "" {
+		from = config.App.SMTPUser
+	}
+
+	return &SMTPNotifier{
+		addr: config.App.SMTPHost + ":" + config.App.SMTPPort,
+		from: from,
+		auth: auth,
+	}
+}
+
+func (s *SMTPNotifier) Send(msg Message) (string, error) {
+	subject := msg.Subject
+	if subject == "" {
+		subject = "PG Manager"
+	}
+
+	messageID := fmt.Sprintf("<%d@pg-manager>", time.Now().UnixNano())
+	headers := []string{
+		"From: " + s.from,
+		"To: " + msg.To,
+		"Subject: " + subject,
+		"Message-ID: " + messageID,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+	}
+	body := strings.Join(headers, "\r\n") + "\r\n\r\n" + msg.Body
+
+	if err := smtp.SendMail(s.addr, s.auth, s.from, []string{msg.To}, []byte(body)); err != nil {
+		return "", fmt.Errorf("smtp error: %v", err)
+	}
+	return messageID, nil
+}
@@ -0,0 +1,98 @@
+package notifier
+
+import (
+	"fmt"
+	"log"
+	"pg-manager-backend/config"
+	"sync"
+)
+
+// Channel names, also stored as a tenant's preferred channel
+const (
+	WhatsApp = "whatsapp"
+	SMS      = "sms"
+	Email    = "email"
+)
+
+// Message is a single outbound notification
+type Message struct {
+	Channel string
+	To      string // Phone number for WhatsApp/SMS, address for email
+	Subject string // Email only
+	Body    string
+
+	// WhatsApp Business pre-approved template; when set, Body is only the fallback text
+	TemplateSID  string
+	TemplateVars map[string]string
+}
+
+// Notifier delivers messages on one channel and returns the provider's message ID
+type Notifier interface {
+	Send(msg Message) (string, error)
+}
+
+var (
+	mu       sync.RWMutex
+	channels map[string]Notifier
+	capture  *Capture
+	once     sync.Once
+)
+
+// setup wires each channel for the configured mode. Live channels without
+// credentials fall back to the log sink so development never sends by accident.
+func setup() {
+	sink := NewLogSink(config.App.NotifyLogFile)
+	channels = map[string]Notifier{WhatsApp: sink, SMS: sink, Email: sink}
+
+	switch config.App.NotifierMode {
+	case "live":
+		if config.App.TwilioSID != "" && config.App.TwilioAuthToken != "" {
+			channels[WhatsApp] = NewTwilioWhatsApp()
+			if config.App.TwilioSMSFrom != "" {
+				channels[SMS] = NewTwilioSMS()
+			}
+		} else {
+			log.Println("⚠️ NOTIFIER_MODE=live but Twilio is not configured; WhatsApp/SMS go to the log sink")
+		}
+		if config.App.SMTPHost != "" {
+			channels[Email] = NewSMTPEmail()
+		} else {
+			log.Println("⚠️ NOTIFIER_MODE=live but SMTP is not configured; email goes to the log sink")
+		}
+	case "capture":
+		capture = &Capture{}
+		channels = map[string]Notifier{WhatsApp: capture, SMS: capture, Email: capture}
+	}
+
+	log.Printf("📣 Notifier ready in [%s] mode", config.App.NotifierMode)
+}
+
+// Register swaps the notifier for a channel, e.g. to point everything at a Capture in tests
+func Register(channel string, n Notifier) {
+	once.Do(setup)
+	mu.Lock()
+	defer mu.Unlock()
+	channels[channel] = n
+}
+
+// Captured returns the in-memory outbox when running with NOTIFIER_MODE=capture
+func Captured() *Capture {
+	once.Do(setup)
+	return capture
+}
+
+// Send routes a message to its channel's notifier
+func Send(msg Message) (string, error) {
+	once.Do(setup)
+
+	mu.RLock()
+	n, ok := channels[msg.Channel]
+	mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("unknown notification channel: %s", msg.Channel)
+	}
+	if msg.To == "" {
+		return "", fmt.Errorf("no %s address for recipient", msg.Channel)
+	}
+	return n.Send(msg)
+}
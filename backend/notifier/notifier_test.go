@@ -0,0 +1,68 @@
+package notifier
+
+import (
+	"os"
+	"pg-manager-backend/config"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	config.App.NotifierMode = "capture"
+	os.Exit(m.Run())
+}
+
+func TestCaptureKeepsEveryMessage(t *testing.T) {
+	Captured().Reset()
+	sent := []Message{
+		{Channel: WhatsApp, To: "+919845012345", Body: "Rent of ₹8000.00 is due on 5 Jan"},
+		{Channel: SMS, To: "+919845012345", Body: "Your OTP is 482913"},
+		{Channel: Email, To: "ravi@example.com", Subject: "Receipt", Body: "Thanks for your payment"},
+	}
+	for _, msg := range sent {
+		if _, err := Send(msg); err != nil {
+			t.Fatalf("Send(%s): %v", msg.Channel, err)
+		}
+	}
+
+	got := Captured().Outbox()
+	if len(got) != len(sent) {
+		t.Fatalf("captured %d messages, want %d", len(got), len(sent))
+	}
+	for i := range sent {
+		if got[i].Channel != sent[i].Channel || got[i].To != sent[i].To || got[i].Body != sent[i].Body {
+			t.Errorf("message %d = %+v, want %+v", i, got[i], sent[i])
+		}
+	}
+
+	Captured().Reset()
+	if n := len(Captured().Outbox()); n != 0 {
+		t.Errorf("outbox has %d messages after Reset", n)
+	}
+}
+
+func TestSendRejectsBadMessages(t *testing.T) {
+	Captured().Reset()
+	if _, err := Send(Message{Channel: "fax", To: "+919845012345", Body: "hi"}); err == nil {
+		t.Error("want an error for an unknown channel")
+	}
+	if _, err := Send(Message{Channel: WhatsApp, Body: "hi"}); err == nil {
+		t.Error("want an error for a message with no recipient")
+	}
+	if n := len(Captured().Outbox()); n != 0 {
+		t.Errorf("captured %d rejected messages", n)
+	}
+}
+
+func TestMaskNumber(t *testing.T) {
+	tests := map[string]string{
+		"+919845012345":          "*********2345",
+		"whatsapp:+919845012345": "******************2345",
+		"1234":                   "****",
+		"":                       "****",
+	}
+	for number, want := range tests {
+		if got := maskNumber(number); got != want {
+			t.Errorf("maskNumber(%q) = %q, want %q", number, got, want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package notifier
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogSink prints messages instead of sending them; used in development and when a channel is not configured
+type LogSink struct {
+	mu   sync.Mutex
+	file string
+}
+
+// NewLogSink logs to stdout, and also appends to file when one is given
+func NewLogSink(file string) *LogSink {
+	return &LogSink{file: file}
+}
+
+func (l *LogSink) Send(msg Message) (string, error) {
+	id := fmt.Sprintf("log-%d", time.Now().UnixNano())
+	entry := fmt.Sprintf("\n--- [NOTIFICATION: %s] ---\nTo: %s\n", msg.Channel, msg.To)
+	if msg.Subject != "" {
+		entry += "Subject: " + msg.Subject + "\n"
+	}
+	entry += "Message: " + msg.Body + "\n"
+
+	log.Print(entry)
+
+	if l.file != "" {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		f, err := os.OpenFile(l.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		if _, err := f.WriteString(time.Now().Format(time.RFC3339) + entry); err != nil {
+			return "", err
+		}
+	}
+	return id, nil
+}
+
+// Capture keeps every message in memory so tests can assert on what would have been sent
+type Capture struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func (c *Capture) Send(msg Message) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, msg)
+	return fmt.Sprintf("capture-%d", len(c.messages)), nil
+}
+
+// Outbox returns a copy of everything captured so far
+func (c *Capture) Outbox() []Message {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Message(nil), c.messages...)
+}
+
+// Reset empties the outbox between test cases
+func (c *Capture) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = nil
+}
@@ -0,0 +1,89 @@
+package notifier
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"pg-manager-backend/config"
+	"pg-manager-backend/utils"
+	"strings"
+
+	"github.com/twilio/twilio-go"
+	openapi "github.com/twilio/twilio-go/rest/api/v2010"
+)
+
+// TwilioNotifier sends WhatsApp or plain SMS through the Twilio Messages API
+type TwilioNotifier struct {
+	client   *twilio.RestClient
+	from     string
+	whatsapp bool
+}
+
+// NewTwilioWhatsApp uses TWILIO_FROM_NUMBER as the WhatsApp sender
+func NewTwilioWhatsApp() *TwilioNotifier {
+	return &TwilioNotifier{client: newTwilioClient(), from: config.App.TwilioFromNumber, whatsapp: true}
+}
+
+// NewTwilioSMS uses TWILIO_SMS_FROM as the SMS sender
+func NewTwilioSMS() *TwilioNotifier {
+	return &TwilioNotifier{client: newTwilioClient(), from: config.App.TwilioSMSFrom}
+}
+
+func newTwilioClient() *twilio.RestClient {
+	return twilio.NewRestClientWithParams(twilio.ClientParams{
+		Username: config.App.TwilioSID,
+		Password: config.App.TwilioAuthToken,
+	})
+}
+
+func (t *TwilioNotifier) Send(msg Message) (string, error) {
+	to, err := utils.NormalizePhone(msg.To)
+	if err != nil {
+		return "", err
+	}
+
+	from := t.from
+	if t.whatsapp {
+		to = "whatsapp:" + to
+		if !strings.HasPrefix(from, "whatsapp:") {
+			from = "whatsapp:" + from
+		}
+	}
+
+	params := &openapi.CreateMessageParams{}
+	params.SetTo(to)
+	params.SetFrom(from)
+	params.SetStatusCallback(config.App.BaseURL + "/api/webhooks/twilio/status")
+
+	// Business-initiated WhatsApp messages must use an approved template
+	if t.whatsapp && msg.TemplateSID != "" {
+		vars, err := json.Marshal(msg.TemplateVars)
+		if err != nil {
+			return "", err
+		}
+		params.SetContentSid(msg.TemplateSID)
+		params.SetContentVariables(string(vars))
+	} else {
+		params.SetBody(msg.Body)
+	}
+
+	resp, err := t.client.Api.CreateMessage(params)
+	if err != nil {
+		return "", fmt.Errorf("twilio api error: %v", err)
+	}
+
+	sid := ""
+	if resp.Sid != nil {
+		sid = *resp.Sid
+		log.Printf("✅ %s sent to %s, SID %s", msg.Channel, maskNumber(to), sid)
+	}
+	return sid, nil
+}
+
+// maskNumber keeps only the last four digits of a phone number for logs
+func maskNumber(number string) string {
+	if len(number) <= 4 {
+		return "****"
+	}
+	return strings.Repeat("*", len(number)-4) + number[len(number)-4:]
+}
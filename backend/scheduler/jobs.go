@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"pg-manager-backend/config"
+	"pg-manager-backend/services"
+)
+
+// Job is a named unit of background work with a cron schedule (in SCHEDULER_TIMEZONE).
+// Run covers every owner when ownerID is 0 (scheduled and admin runs), else only that owner's properties.
+type Job struct {
+	Name        string
+	Description string
+	Schedule    string
+	Run         func(ownerID uint) error
+	AdminOnly   bool // Work that cannot be split per owner
+}
+
+// registry lists every job the scheduler knows; manual triggers use the same names
+var registry = []Job{
+	{
+		Name:        "billing",
+		Description: "Bill tenants whose 30-day cycle ends today and raise their invoices",
+		Schedule:    "0 9 * * *",
+		Run:         services.ProcessDailyBilling,
+	},
+	{
+		Name:        "reminders",
+		Description: "Send scheduled rent reminders outside each property's quiet hours",
+		Schedule:    "0 * * * *",
+		Run:         services.ProcessRentReminders,
+	},
+	{
+		Name:        "late_fees",
+		Description: "Raise late fee invoices for rent unpaid after the grace period",
+		Schedule:    "30 9 * * *",
+		Run:         services.ApplyLateFees,
+	},
+	{
+		Name:        "overdue_alerts",
+		Description: "Tell owners about tenants who just crossed their overdue threshold",
+		Schedule:    "0 10 * * *",
+		Run:         services.ProcessOverdueAlerts,
+	},
+	{
+		Name:        "owner_digest",
+		Description: "Send owners the daily digest of collections, dues and complaints",
+		Schedule:    "0 20 * * *",
+		Run:         services.SendOwnerDailyDigests,
+	},
+	{
+		Name:        "receipt_cleanup",
+		Description: "Delete generated receipt and invoice PDFs past the retention period",
+		Schedule:    "0 3 * * *",
+		Run:         func(uint) error { return services.CleanupGeneratedFiles(config.App.ReceiptRetentionDays) },
+		AdminOnly:   true,
+	},
+	{
+		Name:        "owner_reports",
+		Description: "Send each owner last month's collections, expenditure and dues",
+		Schedule:    "0 8 1 * *",
+		Run:         services.SendOwnerMonthlyReports,
+	},
+}
+
+func findJob(name string) (Job, bool) {
+	for _, job := range registry {
+		if job.Name == name {
+			return job, true
+		}
+	}
+	return Job{}, false
+}
@@ -0,0 +1,41 @@
+package scheduler
+
+import (
+	"hash/fnv"
+	"pg-manager-backend/config"
+
+	"gorm.io/gorm"
+)
+
+// lockKey maps a job name to the bigint key Postgres advisory locks use
+func lockKey(jobName string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("pg-manager:job:" + jobName))
+	return int64(h.Sum64())
+}
+
+// withAdvisoryLock runs fn while holding a session-level advisory lock for the job.
+// The lock lives on one pinned connection, so it is released even if the replica dies mid-run.
+// Returns false without running fn when another replica holds the lock.
+func withAdvisoryLock(jobName string, fn func() error) (bool, error) {
+	key := lockKey(jobName)
+	acquired := false
+	var runErr error
+
+	err := config.DB.Connection(func(conn *gorm.DB) error {
+		if err := conn.Raw("SELECT pg_try_advisory_lock(?)", key).Scan(&acquired).Error; err != nil {
+			return err
+		}
+		if !acquired {
+			return nil
+		}
+		defer conn.Exec("SELECT pg_advisory_unlock(?)", key)
+
+		runErr = fn()
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return acquired, runErr
+}
@@ -0,0 +1,229 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"sync"
+	"time"
+
+	"github.com/go-co-op/gocron"
+	"gorm.io/gorm"
+)
+
+// JobStatus is what the status endpoint reports for each registered job
+type JobStatus struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	Schedule    string         `json:"schedule"`
+	NextRun     *time.Time     `json:"next_run"` // Nil when this replica is not running the scheduler
+	Running     bool           `json:"running"`
+	LastRun     *models.JobRun `json:"last_run"`
+}
+
+// No job should take this long; a run still marked running after it is treated as abandoned
+const staleRunAfter = 6 * time.Hour
+
+var (
+	cron     *gocron.Scheduler
+	cronJobs = map[string]*gocron.Job{}
+	location = time.Local
+	mu       sync.Mutex
+)
+
+// Start registers every job with gocron and runs them until ctx is cancelled.
+// Call it once from main after the database is connected; every replica may call it,
+// the advisory locks make sure each tick runs on only one of them.
+func Start(ctx context.Context) error {
+	if !config.App.SchedulerEnabled {
+		log.Println("⏸️ Scheduler disabled (SCHEDULER_ENABLED=false)")
+		return nil
+	}
+
+	if loc, err := time.LoadLocation(config.App.SchedulerTimezone); err == nil {
+		location = loc
+	} else {
+		log.Printf("⚠️ Unknown SCHEDULER_TIMEZONE %q, using local time", config.App.SchedulerTimezone)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	cron = gocron.NewScheduler(location)
+	for _, job := range registry {
+		job := job
+		scheduled, err := cron.Cron(job.Schedule).Tag(job.Name).Do(func() {
+			runJob(job, "schedule", 0, 0)
+		})
+		if err != nil {
+			return fmt.Errorf("could not schedule %s: %v", job.Name, err)
+		}
+		cronJobs[job.Name] = scheduled
+	}
+
+	cron.StartAsync()
+	log.Printf("⏱️ Scheduler started with %d jobs (%s)", len(registry), location)
+
+	go func() {
+		<-ctx.Done()
+		cron.Stop()
+		log.Println("⏱️ Scheduler stopped")
+	}()
+	return nil
+}
+
+// RunNow triggers a job outside its schedule, for every owner when ownerID is 0 (admins) or for one
+// owner's properties. It returns immediately; the outcome is in the run history.
+func RunNow(name string, userID, ownerID uint) error {
+	job, ok := findJob(name)
+	if !ok {
+		return errors.New("job not found")
+	}
+	if ownerID != 0 && job.AdminOnly {
+		return errors.New("only admins can run this job")
+	}
+
+	// A "running" row older than staleRunAfter belongs to a replica that died mid-run
+	var running int64
+	config.DB.Model(&models.JobRun{}).Where("job_name = ? AND status = ? AND started_at > ?", name, "running", time.Now().Add(-staleRunAfter)).
+		Count(&running)
+	if running > 0 {
+		return errors.New("job is already running")
+	}
+
+	go runJob(job, "manual", userID, ownerID)
+	return nil
+}
+
+// runJob takes the job's advisory lock, records the run and recovers from panics so one bad run never kills the scheduler.
+// The lock is per job, so an owner's run and the scheduled run never work on the same tenants at once.
+func runJob(job Job, trigger string, userID, ownerID uint) {
+	host, _ := os.Hostname()
+	run := models.JobRun{JobName: job.Name, Trigger: trigger, TriggeredBy: userID, OwnerID: ownerID, Host: host}
+	if trigger == "schedule" {
+		tick := time.Now().In(location).Truncate(time.Minute)
+		run.ScheduledFor = &tick
+	}
+
+	acquired, err := withAdvisoryLock(job.Name, func() error {
+		// Another replica may already have finished this tick before we got the lock
+		if run.ScheduledFor != nil {
+			var done int64
+			config.DB.Model(&models.JobRun{}).Where("job_name = ? AND scheduled_for = ?", job.Name, run.ScheduledFor).Count(&done)
+			if done > 0 {
+				return nil
+			}
+		}
+
+		run.Status = "running"
+		run.StartedAt = time.Now()
+		config.DB.Create(&run)
+
+		runErr := safeRun(job, ownerID)
+
+		finished := time.Now()
+		run.FinishedAt = &finished
+		run.DurationMs = finished.Sub(run.StartedAt).Milliseconds()
+		run.Status = "succeeded"
+		if runErr != nil {
+			run.Status = "failed"
+			run.Error = runErr.Error()
+			log.Printf("❌ Job %s failed after %dms: %v", job.Name, run.DurationMs, runErr)
+		} else {
+			log.Printf("✅ Job %s finished in %dms", job.Name, run.DurationMs)
+		}
+		return config.DB.Save(&run).Error
+	})
+
+	if err != nil && run.ID == 0 {
+		log.Printf("⚠️ Job %s could not start: %v", job.Name, err)
+		return
+	}
+
+	// Manual runs that lose the lock are recorded so the owner sees why nothing happened
+	if !acquired && trigger == "manual" {
+		now := time.Now()
+		config.DB.Create(&models.JobRun{
+			JobName: job.Name, Trigger: trigger, TriggeredBy: userID, OwnerID: ownerID, Host: host,
+			Status: "skipped", Error: "this job is already running",
+			StartedAt: now, FinishedAt: &now,
+		})
+	}
+}
+
+func safeRun(job Job, ownerID uint) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(ownerID)
+}
+
+// visibleRuns limits run history to what the viewer may see: admins (ownerID 0) see every run, owners see
+// their own runs and the ones covering every owner, with the errors of the latter hidden in forOwner
+func visibleRuns(query *gorm.DB, ownerID uint) *gorm.DB {
+	if ownerID == 0 {
+		return query
+	}
+	return query.Where("owner_id IN ?", []uint{0, ownerID})
+}
+
+// forOwner hides the error of an all-owner run, which can name other owners' tenants
+func forOwner(run *models.JobRun, ownerID uint) {
+	if ownerID != 0 && run.OwnerID != ownerID && run.Error != "" {
+		run.Error = "failed for some properties; ask an admin for details"
+	}
+}
+
+// Status lists the jobs the viewer can run (all for admins, ownerID 0) with their next tick on this
+// replica and their most recent run
+func Status(ownerID uint) []JobStatus {
+	mu.Lock()
+	defer mu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(registry))
+	for _, job := range registry {
+		if ownerID != 0 && job.AdminOnly {
+			continue
+		}
+		status := JobStatus{Name: job.Name, Description: job.Description, Schedule: job.Schedule}
+
+		if scheduled, ok := cronJobs[job.Name]; ok {
+			next := scheduled.NextRun()
+			status.NextRun = &next
+		}
+
+		var last models.JobRun
+		query := visibleRuns(config.DB.Where("job_name = ? AND status <> ?", job.Name, "skipped"), ownerID)
+		if err := query.Order("started_at DESC").First(&last).Error; err == nil {
+			forOwner(&last, ownerID)
+			status.LastRun = &last
+			status.Running = last.Status == "running" && time.Since(last.StartedAt) < staleRunAfter
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// History returns a job's most recent runs the viewer may see (all for admins, ownerID 0), newest first
+func History(name string, limit int, ownerID uint) ([]models.JobRun, error) {
+	job, ok := findJob(name)
+	if !ok || (ownerID != 0 && job.AdminOnly) {
+		return nil, errors.New("job not found")
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	var runs []models.JobRun
+	err := visibleRuns(config.DB.Where("job_name = ?", name), ownerID).Order("started_at DESC").Limit(limit).Find(&runs).Error
+	for i := range runs {
+		forOwner(&runs[i], ownerID)
+	}
+	return runs, err
+}
@@ -0,0 +1,261 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TenantAccount is the allocation view of a tenant: what is owed per invoice and what is held as credit
+type TenantAccount struct {
+	Balance      float64          `json:"balance"`
+	Credit       float64          `json:"credit"`
+	OpenInvoices []models.Invoice `json:"open_invoices"`
+}
+
+// allocatePayment spreads a payment's unallocated amount over open invoices.
+// Owner-chosen invoices are settled first, then the rest oldest due date first; leftovers stay as credit.
+func allocatePayment(tx *gorm.DB, payment *models.Payment, preferred []uint) error {
+	if payment.Amount <= 0 {
+		return nil
+	}
+	payment.Unallocated = payment.Amount
+
+	var invoices []models.Invoice
+	if err := tx.Where("tenant_id = ? AND status IN ?", payment.TenantID, []string{"open", "partial"}).
+		Order("due_date asc, id asc").Find(&invoices).Error; err != nil {
+		return err
+	}
+
+	for _, inv := range orderByPreference(invoices, preferred) {
+		if payment.Unallocated < 0.01 {
+			break
+		}
+		if err := allocateToInvoice(tx, payment, inv); err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(payment).Update("unallocated", roundPaise(payment.Unallocated)).Error
+}
+
+// applyCredit uses a tenant's carry-forward credit (oldest payment first) against a new invoice
+func applyCredit(tx *gorm.DB, invoice *models.Invoice) error {
+	var payments []models.Payment
+	if err := tx.Where("tenant_id = ? AND unallocated > ? AND status = ?", invoice.TenantID, 0.009, "completed").
+		Order("date asc, id asc").Find(&payments).Error; err != nil {
+		return err
+	}
+
+	for i := range payments {
+		if invoice.Amount-invoice.AmountPaid < 0.01 {
+			break
+		}
+		if err := allocateToInvoice(tx, &payments[i], *invoice); err != nil {
+			return err
+		}
+		if err := tx.Model(&payments[i]).Update("unallocated", roundPaise(payments[i].Unallocated)).Error; err != nil {
+			return err
+		}
+		// Keep the caller's copy in step so messages show the right amount due
+		if err := tx.First(invoice, invoice.ID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allocateToInvoice moves as much of the payment's unallocated amount as the invoice still needs
+func allocateToInvoice(tx *gorm.DB, payment *models.Payment, inv models.Invoice) error {
+	due := inv.Amount - inv.AmountPaid
+	take := roundPaise(math.Min(due, payment.Unallocated))
+	if take < 0.01 {
+		return nil
+	}
+
+	allocation := models.PaymentAllocation{
+		PaymentID: payment.ID,
+		InvoiceID: inv.ID,
+		TenantID:  payment.TenantID,
+		Amount:    take,
+	}
+	if err := tx.Create(&allocation).Error; err != nil {
+		return err
+	}
+
+	payment.Unallocated -= take
+	return updateInvoicePaid(tx, inv, inv.AmountPaid+take)
+}
+
+// releaseAllocations undoes everything a reversed payment paid for, so those invoices are owed again
+func releaseAllocations(tx *gorm.DB, original models.Payment, reversal models.Payment) error {
+	var allocations []models.PaymentAllocation
+	if err := tx.Where("payment_id = ?", original.ID).Find(&allocations).Error; err != nil {
+		return err
+	}
+
+	for _, alloc := range allocations {
+		offset := models.PaymentAllocation{
+			PaymentID: reversal.ID,
+			InvoiceID: alloc.InvoiceID,
+			TenantID:  alloc.TenantID,
+			Amount:    -alloc.Amount,
+		}
+		if err := tx.Create(&offset).Error; err != nil {
+			return err
+		}
+
+		var inv models.Invoice
+		if err := tx.First(&inv, alloc.InvoiceID).Error; err != nil {
+			return err
+		}
+		if err := updateInvoicePaid(tx, inv, inv.AmountPaid-alloc.Amount); err != nil {
+			return err
+		}
+	}
+
+	// Any credit the original was carrying disappears with it
+	return tx.Model(&original).Update("unallocated", 0).Error
+}
+
+// consumeCredit reduces carry-forward credit when it is refunded; the chosen payment's credit goes first
+func consumeCredit(tx *gorm.DB, tenantID uint, amount float64, preferredPaymentID *uint) error {
+	var payments []models.Payment
+	q := tx.Where("tenant_id = ? AND unallocated > ? AND status = ?", tenantID, 0.009, "completed")
+	if preferredPaymentID != nil {
+		q = q.Order(gorm.Expr("CASE WHEN id = ? THEN 0 ELSE 1 END", *preferredPaymentID))
+	}
+	if err := q.Order("date asc, id asc").Find(&payments).Error; err != nil {
+		return err
+	}
+
+	for _, p := range payments {
+		if amount < 0.01 {
+			break
+		}
+		take := math.Min(p.Unallocated, amount)
+		if err := tx.Model(&p).Update("unallocated", roundPaise(p.Unallocated-take)).Error; err != nil {
+			return err
+		}
+		amount -= take
+	}
+	return nil
+}
+
+func updateInvoicePaid(tx *gorm.DB, inv models.Invoice, paid float64) error {
+	paid = roundPaise(paid)
+	updates := map[string]interface{}{"amount_paid": paid}
+
+	switch {
+	case paid >= inv.Amount-0.009:
+		now := time.Now()
+		updates["status"] = "paid"
+		updates["paid_at"] = &now
+	case paid > 0:
+		updates["status"] = "partial"
+		updates["paid_at"] = nil
+	default:
+		updates["status"] = "open"
+		updates["paid_at"] = nil
+	}
+	return tx.Model(&models.Invoice{}).Where("id = ?", inv.ID).Updates(updates).Error
+}
+
+// orderByPreference puts the owner's chosen invoices first, keeping the rest in due-date order
+func orderByPreference(invoices []models.Invoice, preferred []uint) []models.Invoice {
+	if len(preferred) == 0 {
+		return invoices
+	}
+
+	ordered := make([]models.Invoice, 0, len(invoices))
+	used := map[uint]bool{}
+	for _, id := range preferred {
+		for _, inv := range invoices {
+			if inv.ID == id && !used[id] {
+				ordered = append(ordered, inv)
+				used[id] = true
+			}
+		}
+	}
+	for _, inv := range invoices {
+		if !used[inv.ID] {
+			ordered = append(ordered, inv)
+		}
+	}
+	return ordered
+}
+
+func roundPaise(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// getTenantCredit is the total unallocated amount across a tenant's payments
+func getTenantCredit(tenantID uint) float64 {
+	var credit float64
+	config.DB.Model(&models.Payment{}).
+		Where("tenant_id = ? AND status = ?", tenantID, "completed").
+		Select("COALESCE(SUM(unallocated), 0)").Scan(&credit)
+	return credit
+}
+
+// getPaymentAllocations returns a payment's allocations with invoice details, for receipts
+func getPaymentAllocations(paymentID uint) []models.PaymentAllocation {
+	var allocations []models.PaymentAllocation
+	config.DB.Preload("Invoice").Where("payment_id = ?", paymentID).Order("id asc").Find(&allocations)
+	return allocations
+}
+
+// GetTenantAccount returns the tenant's open invoices and credit for the owner
+func GetTenantAccount(tenantID, ownerID uint) (TenantAccount, error) {
+	var profile models.TenantProfile
+	if err := config.DB.First(&profile, "user_id = ?", tenantID).Error; err != nil {
+		return TenantAccount{}, errors.New("tenant profile not found")
+	}
+	if err := verifyPropertyOwner(profile.PropertyID, ownerID); err != nil {
+		return TenantAccount{}, err
+	}
+
+	account := TenantAccount{Balance: profile.Balance, Credit: getTenantCredit(tenantID), OpenInvoices: []models.Invoice{}}
+	err := config.DB.Where("tenant_id = ? AND status IN ?", tenantID, []string{"open", "partial"}).
+		Order("due_date asc").Find(&account.OpenInvoices).Error
+	return account, err
+}
+
+// AllocateCredit lets the owner apply a tenant's carry-forward credit to specific invoices
+func AllocateCredit(tenantID, ownerID uint, invoiceIDs []uint) (TenantAccount, error) {
+	var profile models.TenantProfile
+	if err := config.DB.First(&profile, "user_id = ?", tenantID).Error; err != nil {
+		return TenantAccount{}, errors.New("tenant profile not found")
+	}
+	if err := verifyPropertyOwner(profile.PropertyID, ownerID); err != nil {
+		return TenantAccount{}, err
+	}
+	if len(invoiceIDs) == 0 {
+		return TenantAccount{}, errors.New("select at least one invoice")
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for _, id := range invoiceIDs {
+			var inv models.Invoice
+			if err := tx.First(&inv, "id = ? AND tenant_id = ?", id, tenantID).Error; err != nil {
+				return errors.New("invoice not found")
+			}
+			if inv.Status == "paid" {
+				continue
+			}
+			if err := applyCredit(tx, &inv); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return TenantAccount{}, err
+	}
+
+	return GetTenantAccount(tenantID, ownerID)
+}
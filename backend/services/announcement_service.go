@@ -0,0 +1,266 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// AnnouncementInput is what the owner submits; only the fields for the chosen target are used
+type AnnouncementInput struct {
+	Title   string  `json:"title" binding:"required"`
+	Body    string  `json:"body" binding:"required"`
+	Target  string  `json:"target"` // "all" (default), "floor", "rooms" or "dues"
+	Floor   string  `json:"floor"`
+	RoomIDs []uint  `json:"room_ids"`
+	MinDue  float64 `json:"min_due"`
+}
+
+// AnnouncementRecipientStatus is a recipient with the delivery state of the last message sent to them
+type AnnouncementRecipientStatus struct {
+	models.AnnouncementRecipient
+	Channel        string `json:"channel"`
+	Status         string `json:"status"`          // Outbox status: pending, sending, sent, dead
+	DeliveryStatus string `json:"delivery_status"` // Provider receipt: delivered, read, failed...
+	LastError      string `json:"last_error"`
+}
+
+// AnnouncementDetail is an announcement with per-recipient delivery and a status tally
+type AnnouncementDetail struct {
+	models.Announcement
+	Summary    map[string]int                `json:"summary"`
+	Recipients []AnnouncementRecipientStatus `json:"recipients"`
+}
+
+// roomFloor is the room's floor, or the room number minus its last two digits ("204" → "2", "G01" → "G")
+func roomFloor(room models.Room) string {
+	if room.Floor != "" {
+		return strings.ToUpper(strings.TrimSpace(room.Floor))
+	}
+	number := strings.ToUpper(strings.TrimSpace(room.RoomNumber))
+	if len(number) <= 2 {
+		return "0"
+	}
+	return number[:len(number)-2]
+}
+
+// announcementAudience returns the active tenants of the property the announcement targets
+func announcementAudience(propertyID uint, input AnnouncementInput) ([]models.TenantProfile, error) {
+	query := config.DB.Where("property_id = ? AND status = ?", propertyID, "active")
+
+	switch input.Target {
+	case "all":
+	case "dues":
+		query = query.Where("balance > ?", input.MinDue)
+	case "rooms":
+		if len(input.RoomIDs) == 0 {
+			return nil, errors.New("invalid target: room_ids is required")
+		}
+		query = query.Where("room_id IN ?", input.RoomIDs)
+	case "floor":
+		if strings.TrimSpace(input.Floor) == "" {
+			return nil, errors.New("invalid target: floor is required")
+		}
+		var rooms []models.Room
+		config.DB.Where("property_id = ?", propertyID).Find(&rooms)
+		var roomIDs []uint
+		for _, room := range rooms {
+			if roomFloor(room) == strings.ToUpper(strings.TrimSpace(input.Floor)) {
+				roomIDs = append(roomIDs, room.ID)
+			}
+		}
+		if len(roomIDs) == 0 {
+			return nil, errors.New("invalid target: no rooms on that floor")
+		}
+		query = query.Where("room_id IN ?", roomIDs)
+	default:
+		return nil, errors.New("invalid target: use all, floor, rooms or dues")
+	}
+
+	var tenants []models.TenantProfile
+	if err := query.Find(&tenants).Error; err != nil {
+		return nil, errors.New("could not load tenants")
+	}
+	return tenants, nil
+}
+
+// SendAnnouncement queues the announcement for every targeted tenant on their preferred channel.
+// Messages are spaced out at ANNOUNCEMENT_MESSAGES_PER_MIN so a large PG does not trip provider limits.
+func SendAnnouncement(propertyID, ownerID uint, input AnnouncementInput) (models.Announcement, error) {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return models.Announcement{}, err
+	}
+
+	input.Title = strings.TrimSpace(input.Title)
+	input.Body = strings.TrimSpace(input.Body)
+	if input.Title == "" || input.Body == "" {
+		return models.Announcement{}, errors.New("invalid announcement: title and body are required")
+	}
+	if input.Target == "" {
+		input.Target = "all"
+	}
+
+	// 1. Resolve the audience
+	tenants, err := announcementAudience(propertyID, input)
+	if err != nil {
+		return models.Announcement{}, err
+	}
+	if len(tenants) == 0 {
+		return models.Announcement{}, errors.New("no tenants match this target")
+	}
+
+	roomIDs := make([]string, len(input.RoomIDs))
+	for i, id := range input.RoomIDs {
+		roomIDs[i] = strconv.FormatUint(uint64(id), 10)
+	}
+
+	announcement := models.Announcement{
+		PropertyID:     propertyID,
+		Title:          input.Title,
+		Body:           input.Body,
+		Target:         input.Target,
+		Floor:          input.Floor,
+		RoomIDs:        strings.Join(roomIDs, ","),
+		MinDue:         input.MinDue,
+		RecipientCount: len(tenants),
+		CreatedBy:      ownerID,
+	}
+
+	// 2. Per-property cap so a misclick cannot spam every tenant repeatedly.
+	// The property row is locked so two concurrent sends cannot both pass the count.
+	tx := config.DB.Begin()
+
+	var property models.Property
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&property, propertyID).Error; err != nil {
+		tx.Rollback()
+		return models.Announcement{}, errors.New("property not found")
+	}
+
+	var recent int64
+	if err := tx.Model(&models.Announcement{}).
+		Where("property_id = ? AND created_at > ?", propertyID, time.Now().Add(-time.Hour)).Count(&recent).Error; err != nil {
+		tx.Rollback()
+		return models.Announcement{}, err
+	}
+	if config.App.AnnouncementsPerHour > 0 && recent >= int64(config.App.AnnouncementsPerHour) {
+		tx.Rollback()
+		return models.Announcement{}, fmt.Errorf("rate limit: at most %d announcements per hour", config.App.AnnouncementsPerHour)
+	}
+
+	if err := tx.Create(&announcement).Error; err != nil {
+		tx.Rollback()
+		return models.Announcement{}, errors.New("could not save announcement")
+	}
+	if err := tx.Commit().Error; err != nil {
+		return models.Announcement{}, errors.New("could not save announcement")
+	}
+
+	// 3. Queue one message per tenant, paced through the outbox
+	gap := time.Duration(0)
+	if config.App.AnnouncementPerMin > 0 {
+		gap = time.Minute / time.Duration(config.App.AnnouncementPerMin)
+	}
+	sendAt := time.Now()
+	queued := 0
+
+	for _, tenant := range tenants {
+		recipient := models.AnnouncementRecipient{
+			AnnouncementID: announcement.ID,
+			TenantID:       tenant.UserID,
+			TenantName:     tenant.Name,
+		}
+
+		record, err := queueTenantMessage(tenant, "announcement", map[string]interface{}{
+			"Title":        announcement.Title,
+			"Body":         announcement.Body,
+			"PropertyName": property.Name,
+		}, sendAt)
+		if err != nil {
+			recipient.SkipReason = err.Error()
+		} else {
+			recipient.OutboundMessageID = &record.ID
+			sendAt = sendAt.Add(gap)
+			queued++
+		}
+		if err := config.DB.Create(&recipient).Error; err != nil {
+			log.Printf("⚠️ Announcement #%d: could not record recipient %d: %v", announcement.ID, tenant.UserID, err)
+		}
+	}
+
+	log.Printf("📢 Announcement #%d queued for %d of %d tenants", announcement.ID, queued, len(tenants))
+	return announcement, nil
+}
+
+// GetAnnouncements lists a property's announcements, newest first
+func GetAnnouncements(propertyID, ownerID uint) ([]models.Announcement, error) {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return nil, err
+	}
+
+	var announcements []models.Announcement
+	err := config.DB.Where("property_id = ?", propertyID).Order("created_at DESC").Find(&announcements).Error
+	return announcements, err
+}
+
+// latestAttempt follows channel fallbacks so the status shown is that of the last channel tried
+func latestAttempt(messageID uint) (models.OutboundMessage, error) {
+	var message models.OutboundMessage
+	if err := config.DB.First(&message, messageID).Error; err != nil {
+		return message, err
+	}
+	for {
+		var next models.OutboundMessage
+		if err := config.DB.Where("fallback_of = ?", message.ID).First(&next).Error; err != nil {
+			return message, nil
+		}
+		message = next
+	}
+}
+
+// GetAnnouncementDetail returns an announcement with each recipient's delivery status
+func GetAnnouncementDetail(announcementID, ownerID uint) (AnnouncementDetail, error) {
+	var announcement models.Announcement
+	if err := config.DB.First(&announcement, announcementID).Error; err != nil {
+		return AnnouncementDetail{}, errors.New("announcement not found")
+	}
+	if err := verifyPropertyOwner(announcement.PropertyID, ownerID); err != nil {
+		return AnnouncementDetail{}, err
+	}
+
+	var recipients []models.AnnouncementRecipient
+	config.DB.Where("announcement_id = ?", announcementID).Order("tenant_name ASC").Find(&recipients)
+
+	detail := AnnouncementDetail{
+		Announcement: announcement,
+		Summary:      map[string]int{},
+		Recipients:   make([]AnnouncementRecipientStatus, 0, len(recipients)),
+	}
+
+	for _, recipient := range recipients {
+		entry := AnnouncementRecipientStatus{AnnouncementRecipient: recipient, Status: "skipped"}
+		if recipient.OutboundMessageID != nil {
+			if message, err := latestAttempt(*recipient.OutboundMessageID); err == nil {
+				entry.Channel = message.Channel
+				entry.Status = message.Status
+				entry.DeliveryStatus = message.DeliveryStatus
+				entry.LastError = message.LastError
+			}
+		}
+
+		key := entry.Status
+		if entry.DeliveryStatus != "" {
+			key = entry.DeliveryStatus
+		}
+		detail.Summary[key]++
+		detail.Recipients = append(detail.Recipients, entry)
+	}
+
+	return detail, nil
+}
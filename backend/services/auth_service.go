@@ -28,7 +28,9 @@ func CheckPasswordHash(password, hash string) bool {
 
 // --- End of New Functions ---
 
-func RegisterUser(name, email, password, role string) error {
+// RegisterUser signs up a PG owner. The role is never taken from the request: tenants are created at
+// admission and admins are promoted in the database.
+func RegisterUser(name, email, password string) error {
 	// Use the new helper function
 	hashedPassword, err := HashPassword(password)
 	if err != nil {
@@ -39,7 +41,7 @@ func RegisterUser(name, email, password, role string) error {
 		Name:     name,
 		Email:    &email,
 		Password: hashedPassword,
-		Role:     role,
+		Role:     "owner",
 	}
 
 	if err := config.DB.Create(&user).Error; err != nil {
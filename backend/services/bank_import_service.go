@@ -0,0 +1,317 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"pg-manager-backend/utils"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// A line needs at least this score before we pre-select a tenant for the owner
+const bankMatchThreshold = 60
+
+var invoiceRefPattern = regexp.MustCompile(`(?i)INV-?(\d{6})`)
+
+// BankLineResponse is a review-queue entry with the suggested tenant's name attached
+type BankLineResponse struct {
+	models.BankStatementLine
+	SuggestedTenantName string `json:"suggested_tenant_name"`
+}
+
+// ImportBankStatement parses an uploaded statement and queues its credits for review
+func ImportBankStatement(propertyID, ownerID uint, fileName string, data []byte) (models.BankStatement, int, error) {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return models.BankStatement{}, 0, err
+	}
+
+	credits, format, err := utils.ParseBankStatement(fileName, data)
+	if err != nil {
+		return models.BankStatement{}, 0, fmt.Errorf("could not read statement: %v", err)
+	}
+
+	// 1. Load everything matching needs up front instead of querying per line
+	var tenants []models.TenantProfile
+	config.DB.Where("property_id = ? AND status = ?", propertyID, "active").Find(&tenants)
+
+	var openInvoices []models.Invoice
+	config.DB.Where("property_id = ? AND status IN ?", propertyID, []string{"open", "partial"}).Find(&openInvoices)
+
+	statement := models.BankStatement{
+		PropertyID: propertyID,
+		UploadedBy: ownerID,
+		FileName:   fileName,
+		Format:     format,
+	}
+
+	skipped := 0
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&statement).Error; err != nil {
+			return err
+		}
+
+		occurrences := map[string]int{}
+		for _, credit := range credits {
+			first := statementFingerprint(propertyID, credit, 1)
+			occurrences[first]++
+			fingerprint := statementFingerprint(propertyID, credit, occurrences[first])
+
+			var existing int64
+			tx.Model(&models.BankStatementLine{}).Where("fingerprint = ?", fingerprint).Count(&existing)
+			if existing > 0 {
+				skipped++ // Overlapping statement periods are normal
+				continue
+			}
+
+			line := models.BankStatementLine{
+				StatementID: statement.ID,
+				PropertyID:  propertyID,
+				TxnDate:     credit.Date,
+				Amount:      credit.Amount,
+				Description: credit.Description,
+				Reference:   credit.Reference,
+				PayerName:   credit.PayerName,
+				Fingerprint: fingerprint,
+				Status:      "unmatched",
+			}
+
+			if credit.Reference != "" && ensureUTRUnused(credit.Reference) != nil {
+				line.Status = "duplicate"
+				line.MatchReason = "reference already recorded as a payment"
+			} else {
+				tenantID, score, reason := matchBankCredit(credit, tenants, openInvoices)
+				line.SuggestedTenantID = tenantID
+				line.MatchScore = score
+				line.MatchReason = reason
+				if tenantID != nil && score >= bankMatchThreshold {
+					line.Status = "suggested"
+				}
+			}
+
+			if err := tx.Create(&line).Error; err != nil {
+				return err
+			}
+			statement.LineCount++
+		}
+
+		return tx.Model(&statement).Update("line_count", statement.LineCount).Error
+	})
+
+	return statement, skipped, err
+}
+
+// matchBankCredit scores every active tenant against a credit and returns the best candidate
+func matchBankCredit(credit utils.StatementCredit, tenants []models.TenantProfile, openInvoices []models.Invoice) (*uint, int, string) {
+	// An invoice reference in the narration is as good as a direct match
+	if m := invoiceRefPattern.FindStringSubmatch(credit.Description); m != nil {
+		ref := "INV-" + m[1]
+		for _, inv := range openInvoices {
+			if inv.Reference == ref {
+				tenantID := inv.TenantID
+				return &tenantID, 100, "invoice reference " + ref + " in narration"
+			}
+		}
+	}
+
+	var bestID *uint
+	bestScore := 0
+	bestReason := ""
+
+	for i := range tenants {
+		t := tenants[i]
+		score := 0
+		var reasons []string
+
+		// 1. Amount: exact invoice amount beats outstanding balance beats monthly rent
+		amountScore := 0
+		for _, inv := range openInvoices {
+			if inv.TenantID == t.UserID && sameAmount(inv.Amount-inv.AmountPaid, credit.Amount) {
+				amountScore = 40
+				reasons = append(reasons, "amount matches invoice "+inv.Reference)
+				break
+			}
+		}
+		if amountScore == 0 && t.Balance > 0 && sameAmount(t.Balance, credit.Amount) {
+			amountScore = 35
+			reasons = append(reasons, "amount matches balance")
+		}
+		if amountScore == 0 && sameAmount(t.MonthlyRent, credit.Amount) {
+			amountScore = 20
+			reasons = append(reasons, "amount matches monthly rent")
+		}
+		score += amountScore
+
+		// 2. Name similarity between payer and tenant
+		if credit.PayerName != "" {
+			similarity := utils.NameSimilarity(credit.PayerName, t.Name)
+			if similarity >= 0.5 {
+				score += int(similarity * 50)
+				reasons = append(reasons, fmt.Sprintf("payer name %.0f%% similar", similarity*100))
+			}
+		}
+
+		// 3. Phone number in the narration (common for IMPS/UPI)
+		if len(t.PhoneNumber) >= 10 && strings.Contains(credit.Description, t.PhoneNumber[len(t.PhoneNumber)-10:]) {
+			score += 30
+			reasons = append(reasons, "tenant phone in narration")
+		}
+
+		if score > bestScore {
+			tenantID := t.UserID
+			bestID = &tenantID
+			bestScore = score
+			bestReason = strings.Join(reasons, "; ")
+		}
+	}
+
+	return bestID, min(bestScore, 100), bestReason
+}
+
+func sameAmount(a, b float64) bool {
+	return math.Abs(a-b) < 0.01
+}
+
+// statementFingerprint identifies the nth identical credit of a statement. Two equal cash deposits on one
+// day are separate lines, while a later statement covering the same day still skips both. The first
+// occurrence keeps the plain key so lines imported earlier are still recognised.
+func statementFingerprint(propertyID uint, credit utils.StatementCredit, occurrence int) string {
+	raw := fmt.Sprintf("%d|%s|%.2f|%s|%s", propertyID, credit.Date.Format("2006-01-02"), credit.Amount, credit.Reference, credit.Description)
+	if occurrence > 1 {
+		raw += fmt.Sprintf("|%d", occurrence)
+	}
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// GetBankReviewQueue lists imported credits that still need an owner decision
+func GetBankReviewQueue(propertyID, ownerID uint) ([]BankLineResponse, error) {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return nil, err
+	}
+
+	var results []BankLineResponse
+	err := config.DB.Table("bank_statement_lines").
+		Select("bank_statement_lines.*, tenant_profiles.name AS suggested_tenant_name").
+		Joins("LEFT JOIN tenant_profiles ON tenant_profiles.user_id = bank_statement_lines.suggested_tenant_id AND tenant_profiles.deleted_at IS NULL").
+		Where("bank_statement_lines.property_id = ? AND bank_statement_lines.status IN ?", propertyID, []string{"unmatched", "suggested"}).
+		Order("bank_statement_lines.match_score desc, bank_statement_lines.txn_date asc").
+		Scan(&results).Error
+
+	return results, err
+}
+
+// ConfirmBankLine posts a reviewed credit as a Payment; tenantID 0 accepts the suggestion
+func ConfirmBankLine(lineID, ownerID, tenantID uint) (models.Payment, float64, error) {
+	var line models.BankStatementLine
+	if err := config.DB.First(&line, lineID).Error; err != nil {
+		return models.Payment{}, 0, errors.New("statement line not found")
+	}
+	if err := verifyPropertyOwner(line.PropertyID, ownerID); err != nil {
+		return models.Payment{}, 0, err
+	}
+	if line.Status != "unmatched" && line.Status != "suggested" {
+		return models.Payment{}, 0, fmt.Errorf("statement line is already %s", line.Status)
+	}
+
+	if tenantID == 0 {
+		if line.SuggestedTenantID == nil {
+			return models.Payment{}, 0, errors.New("no tenant selected for this line")
+		}
+		tenantID = *line.SuggestedTenantID
+	}
+	if line.Reference != "" {
+		if err := ensureUTRUnused(line.Reference); err != nil {
+			return models.Payment{}, 0, err
+		}
+	}
+
+	tx := config.DB.Begin()
+
+	// Claim the line first so a double click or a racing bulk confirm posts it only once
+	claim := tx.Model(&models.BankStatementLine{}).
+		Where("id = ? AND status IN ?", line.ID, []string{"unmatched", "suggested"}).
+		Update("status", "posted")
+	if claim.Error != nil {
+		tx.Rollback()
+		return models.Payment{}, 0, claim.Error
+	}
+	if claim.RowsAffected != 1 {
+		tx.Rollback()
+		return models.Payment{}, 0, errors.New("statement line is already posted")
+	}
+
+	var profile models.TenantProfile
+	if err := tx.First(&profile, "user_id = ? AND property_id = ?", tenantID, line.PropertyID).Error; err != nil {
+		tx.Rollback()
+		return models.Payment{}, 0, errors.New("tenant profile not found")
+	}
+
+	paymentRecord := models.Payment{
+		Amount:      line.Amount,
+		PaymentType: "Rent-Payment",
+		Method:      "Bank Transfer",
+		Reference:   line.Reference,
+		Date:        line.TxnDate,
+	}
+
+	newBalance, err := applyPayment(tx, &profile, &paymentRecord, nil)
+	if err != nil {
+		tx.Rollback()
+		return models.Payment{}, 0, utrConflict(err)
+	}
+
+	if err := tx.Model(&line).Updates(map[string]interface{}{
+		"payment_id":          paymentRecord.ID,
+		"suggested_tenant_id": tenantID,
+	}).Error; err != nil {
+		tx.Rollback()
+		return models.Payment{}, 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return models.Payment{}, 0, err
+	}
+
+	sendPaymentConfirmation(paymentRecord, profile, newBalance)
+
+	return paymentRecord, newBalance, nil
+}
+
+// ConfirmSuggestedBankLines posts every high-confidence suggestion on a statement in one go
+func ConfirmSuggestedBankLines(statementID, ownerID uint) (int, []string) {
+	var lines []models.BankStatementLine
+	config.DB.Where("statement_id = ? AND status = ?", statementID, "suggested").Find(&lines)
+
+	posted := 0
+	var failures []string
+	for _, line := range lines {
+		if _, _, err := ConfirmBankLine(line.ID, ownerID, 0); err != nil {
+			failures = append(failures, fmt.Sprintf("line %d: %v", line.ID, err))
+			continue
+		}
+		posted++
+	}
+	return posted, failures
+}
+
+// IgnoreBankLine removes a non-rent credit (refunds, interest, etc.) from the review queue
+func IgnoreBankLine(lineID, ownerID uint) error {
+	var line models.BankStatementLine
+	if err := config.DB.First(&line, lineID).Error; err != nil {
+		return errors.New("statement line not found")
+	}
+	if err := verifyPropertyOwner(line.PropertyID, ownerID); err != nil {
+		return err
+	}
+	if line.Status == "posted" {
+		return errors.New("statement line is already posted")
+	}
+	return config.DB.Model(&line).Update("status", "ignored").Error
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"pg-manager-backend/models"
+	"pg-manager-backend/utils"
+	"testing"
+	"time"
+)
+
+func TestMatchBankCredit(t *testing.T) {
+	tenants := []models.TenantProfile{
+		{UserID: 1, Name: "Ravi Kumar", PhoneNumber: "+919845012345", MonthlyRent: 8000},
+		{UserID: 2, Name: "Priya Sharma", PhoneNumber: "+919900112233", MonthlyRent: 7500, Balance: 7500},
+		{UserID: 3, Name: "Anita Rao", PhoneNumber: "+918123456789", MonthlyRent: 9000},
+	}
+	invoices := []models.Invoice{
+		{TenantID: 1, Reference: "INV-000045", Amount: 8000},
+		{TenantID: 3, Reference: "INV-000046", Amount: 9000, AmountPaid: 4000},
+	}
+
+	tests := []struct {
+		name      string
+		credit    utils.StatementCredit
+		wantID    uint // 0 = no suggestion
+		wantScore int
+		suggested bool
+	}{
+		{"invoice reference in narration", utils.StatementCredit{Amount: 1234, Description: "NEFT rent inv000046"}, 3, 100, true},
+		{"invoice amount and payer name", utils.StatementCredit{Amount: 8000, PayerName: "RAVI KUMAR"}, 1, 90, true},
+		{"balance and initials", utils.StatementCredit{Amount: 7500, PayerName: "P SHARMA"}, 2, 85, true},
+		{"rest of a part-paid invoice", utils.StatementCredit{Amount: 5000, PayerName: "SOMEONE ELSE"}, 3, 40, false},
+		{"phone in narration", utils.StatementCredit{Amount: 100, Description: "IMPS/9845012345/deposit"}, 1, 30, false},
+		{"nobody matches", utils.StatementCredit{Amount: 123, PayerName: "XYZ TRADERS"}, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, score, reason := matchBankCredit(tt.credit, tenants, invoices)
+			var gotID uint
+			if id != nil {
+				gotID = *id
+			}
+			if gotID != tt.wantID || score != tt.wantScore {
+				t.Errorf("got tenant %d score %d (%s), want tenant %d score %d", gotID, score, reason, tt.wantID, tt.wantScore)
+			}
+			if suggested := id != nil && score >= bankMatchThreshold; suggested != tt.suggested {
+				t.Errorf("suggested = %v, want %v", suggested, tt.suggested)
+			}
+		})
+	}
+}
+
+func TestStatementFingerprintCountsIdenticalLines(t *testing.T) {
+	deposit := utils.StatementCredit{Date: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), Amount: 8000, Description: "CASH DEPOSIT"}
+
+	first := statementFingerprint(1, deposit, 1)
+	second := statementFingerprint(1, deposit, 2)
+	if first == second {
+		t.Error("two identical deposits in one statement share a fingerprint")
+	}
+	if first != statementFingerprint(1, deposit, 1) || second != statementFingerprint(1, deposit, 2) {
+		t.Error("fingerprint is not stable across imports")
+	}
+	if first == statementFingerprint(2, deposit, 1) {
+		t.Error("fingerprint does not depend on the property")
+	}
+}
+
+func TestImportKeepsIdenticalDepositsAndSkipsOverlap(t *testing.T) {
+	requireDB(t)
+	property, _ := createTestTenant(t, models.TenantProfile{Name: "Ravi Kumar", MonthlyRent: 8000})
+	statement := []byte("Date,Narration,Deposit Amt.\n05/01/25,CASH DEPOSIT,8000.00\n05/01/25,CASH DEPOSIT,8000.00\n")
+
+	first, skipped, err := ImportBankStatement(property.ID, property.OwnerID, "jan.csv", statement)
+	if err != nil {
+		t.Fatalf("first import: %v", err)
+	}
+	if first.LineCount != 2 || skipped != 0 {
+		t.Errorf("first import queued %d lines and skipped %d, want 2 and 0", first.LineCount, skipped)
+	}
+
+	// The next month's statement overlaps the same day
+	again, skipped, err := ImportBankStatement(property.ID, property.OwnerID, "jan-feb.csv", statement)
+	if err != nil {
+		t.Fatalf("second import: %v", err)
+	}
+	if again.LineCount != 0 || skipped != 2 {
+		t.Errorf("second import queued %d lines and skipped %d, want 0 and 2", again.LineCount, skipped)
+	}
+}
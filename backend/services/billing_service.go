@@ -9,15 +9,20 @@ import (
 	"time"
 )
 
-func ProcessDailyBilling() {
+// ProcessDailyBilling bills every tenant whose cycle ends today, only the owner's when ownerID is set;
+// an error means some tenants were not billed
+func ProcessDailyBilling(ownerID uint) error {
 	var tenants []models.TenantProfile
 	today := time.Now().Truncate(24 * time.Hour)
 
-	if err := config.DB.Where("status = ? AND next_billing_date <= ?", "active", today).Find(&tenants).Error; err != nil {
+	query := ownerScope(config.DB, "property_id", ownerID)
+	if err := query.Where("status = ? AND next_billing_date <= ?", "active", today).Find(&tenants).Error; err != nil {
 		log.Printf("Error fetching due tenants: %v", err)
-		return
+		return err
 	}
 
+	failed := 0
+
 	for _, tenant := range tenants {
 		tx := config.DB.Begin()
 
@@ -32,6 +37,15 @@ func ProcessDailyBilling() {
 
 		if err := tx.Model(&tenant).Updates(updates).Error; err != nil {
 			tx.Rollback()
+			failed++
+			continue
+		}
+
+		invoice, err := createInvoice(tx, tenant, tenant.MonthlyRent, "Monthly Rent", today)
+		if err != nil {
+			log.Printf("⚠️ Invoice creation failed for %s: %v", tenant.Name, err)
+			tx.Rollback()
+			failed++
 			continue
 		}
 		tx.Commit()
@@ -43,18 +57,21 @@ func ProcessDailyBilling() {
 			paymentLink = "[Link Unavailable]"
 		}
 
-		// TERMINAL LOGGING
-		fmt.Println("\n--- [TERMINAL WHATSAPP SIMULATION: MONTHLY BILL] ---")
-		fmt.Printf("To: %s (%s)\n", tenant.Name, tenant.PhoneNumber)
-		fmt.Printf("Message: Namaste %s! 🏠\n"+
-			"Your monthly rent of ₹%.2f is due. Total Balance: ₹%.2f.\n"+
-			"Click here to pay: %s\n",
-			tenant.Name, tenant.MonthlyRent, newBalance, paymentLink)
-
-		// KEEPING THIS COMMENTED AS REQUESTED
-		/*
-		   message := fmt.Sprintf("Namaste %s! Your rent is due. Pay here: %s", tenant.Name, paymentLink)
-		   utils.SendWhatsAppMessage(tenant.PhoneNumber, message)
-		*/
+		invoiceURL, upiLink := invoicePaymentDetails(invoice, tenant.Name)
+
+		notifyTenant(tenant, "rent_due", map[string]interface{}{
+			"Rent":       tenant.MonthlyRent,
+			"Balance":    newBalance,
+			"AmountDue":  invoice.Amount - invoice.AmountPaid,
+			"PayLink":    paymentLink,
+			"Reference":  invoice.Reference,
+			"UPILink":    upiLink,
+			"InvoiceURL": invoiceURL,
+		})
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("billing failed for %d of %d tenants", failed, len(tenants))
 	}
+	return nil
 }
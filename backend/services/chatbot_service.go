@@ -0,0 +1,219 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"pg-manager-backend/notifier"
+	"pg-manager-backend/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A conversation left half-way is forgotten after this long
+const chatSessionTimeout = 15 * time.Minute
+
+var complaintCategories = []string{"Plumbing", "Electrical", "Cleaning", "WiFi", "Food", "Other"}
+
+const chatHelpText = "🤖 PG Assistant\n" +
+	"BALANCE — Your current dues\n" +
+	"PAY — Fresh payment link\n" +
+	"RECEIPT — Your last payment receipt\n" +
+	"COMPLAINT <issue> — Report a problem\n" +
+	"CANCEL — Stop the current step\n" +
+	"HELP — Show this menu"
+
+// HandleChatbotMessage answers a tenant's inbound WhatsApp message and queues the reply.
+// Commands are case-insensitive; anything mid-conversation is routed by the session state.
+func HandleChatbotMessage(inbound models.InboundMessage, profile models.TenantProfile) {
+	if profile.UserID == 0 {
+		replyToSender(inbound, 0, 0, "Sorry, this number is not registered with any PG. Please contact your PG owner.")
+		return
+	}
+
+	if handleConsentKeyword(inbound, profile) {
+		return
+	}
+
+	session := loadChatSession(profile.UserID)
+	text := strings.TrimSpace(inbound.Body)
+	command, args := splitCommand(text)
+
+	var reply string
+	switch {
+	case command == "CANCEL":
+		session.State, session.Pending = "idle", ""
+		reply = "Okay, cancelled. Send HELP to see what I can do."
+	case session.State == "complaint_description":
+		reply = chatComplaintDescription(&session, text)
+	case session.State == "complaint_category":
+		reply = chatComplaintCategory(&session, profile, text)
+	default:
+		reply = chatCommand(&session, profile, command, args)
+	}
+
+	config.DB.Save(&session)
+	replyToSender(inbound, profile.PropertyID, profile.UserID, reply)
+}
+
+// splitCommand returns the upper-cased first word and the rest of the message
+func splitCommand(text string) (string, string) {
+	parts := strings.SplitN(text, " ", 2)
+	command := strings.ToUpper(strings.TrimSpace(parts[0]))
+	args := ""
+	if len(parts) > 1 {
+		args = strings.TrimSpace(parts[1])
+	}
+	return command, args
+}
+
+func loadChatSession(tenantID uint) models.ChatSession {
+	var session models.ChatSession
+	if err := config.DB.Where("tenant_id = ?", tenantID).First(&session).Error; err != nil {
+		return models.ChatSession{TenantID: tenantID, State: "idle"}
+	}
+	if time.Since(session.UpdatedAt) > chatSessionTimeout {
+		session.State, session.Pending = "idle", ""
+	}
+	return session
+}
+
+func chatCommand(session *models.ChatSession, profile models.TenantProfile, command, args string) string {
+	switch command {
+	case "BALANCE":
+		balance, err := GetTenantBalance(profile.UserID)
+		if err != nil {
+			return "⚠️ " + err.Error()
+		}
+		if balance < 0 {
+			return fmt.Sprintf("💰 You have an advance credit of ₹%.2f. No dues!", -balance)
+		}
+		return fmt.Sprintf("💰 Your current balance is ₹%.2f. Send PAY for a payment link.", balance)
+
+	case "PAY":
+		return chatPaymentLink(profile)
+
+	case "RECEIPT":
+		return chatLastReceipt(profile)
+
+	case "COMPLAINT":
+		if complaintLimitReached(config.DB, profile.UserID) {
+			return "⏳ You can raise " + strings.TrimPrefix(complaintLimitError().Error(), "rate limit: ") + ". Please try again later."
+		}
+		if args == "" {
+			session.State = "complaint_description"
+			return "🛠️ Please describe the problem in one message."
+		}
+		return chatComplaintDescription(session, args)
+
+	case "HELP", "HI", "HELLO", "MENU":
+		return chatHelpText
+	}
+
+	return "Sorry, I didn't understand that.\n\n" + chatHelpText
+}
+
+// chatPaymentLink reads the balance fresh from the database so the link always matches current dues
+func chatPaymentLink(profile models.TenantProfile) string {
+	var current models.TenantProfile
+	if err := config.DB.Where("user_id = ?", profile.UserID).First(&current).Error; err != nil {
+		return "⚠️ Could not find your account. Please contact your PG owner."
+	}
+	if current.Balance <= 0 {
+		return "✅ You have no dues right now."
+	}
+
+	reply := fmt.Sprintf("💳 Amount due: ₹%.2f", current.Balance)
+
+	link, err := utils.GenerateRazorpayLink(current.UserID, current.MailID, current.Balance, "Balance Payment")
+	if err != nil {
+		log.Printf("⚠️ Chatbot pay link failed for %s: %v", current.Name, err)
+	} else {
+		reply += "\nPay here: " + link
+	}
+
+	var invoice models.Invoice
+	if err := config.DB.Where("tenant_id = ? AND status IN ?", current.UserID, []string{"open", "partial"}).
+		Order("due_date ASC").First(&invoice).Error; err == nil {
+		if _, upiLink := invoicePaymentDetails(invoice, current.Name); upiLink != "" {
+			reply += fmt.Sprintf("\nOr pay ₹%.2f by UPI (Ref %s): %s", invoice.Amount-invoice.AmountPaid, invoice.Reference, upiLink)
+		}
+	}
+
+	return reply
+}
+
+func chatLastReceipt(profile models.TenantProfile) string {
+	var payment models.Payment
+	if err := config.DB.Where("tenant_id = ? AND status = ? AND amount > 0", profile.UserID, "completed").
+		Order("date DESC").First(&payment).Error; err != nil {
+		return "ℹ️ No payments found on your account yet."
+	}
+
+	key, err := renderReceipt(payment, profile.Name)
+	if err != nil {
+		log.Printf("⚠️ Chatbot receipt failed for payment #%d: %v", payment.ID, err)
+		return "⚠️ Could not prepare your receipt right now. Please try again later."
+	}
+
+	return fmt.Sprintf("🧾 Receipt for ₹%.2f paid on %s:\n%s",
+		payment.Amount, payment.Date.Format("02 Jan 2006"), downloadURL(key))
+}
+
+func chatComplaintDescription(session *models.ChatSession, text string) string {
+	if len(text) < 5 {
+		session.State = "complaint_description"
+		return "Please describe the problem in a few more words."
+	}
+
+	session.State = "complaint_category"
+	session.Pending = text
+
+	var options strings.Builder
+	options.WriteString("Which category fits best? Reply with a number:")
+	for i, category := range complaintCategories {
+		options.WriteString(fmt.Sprintf("\n%d. %s", i+1, category))
+	}
+	return options.String()
+}
+
+func chatComplaintCategory(session *models.ChatSession, profile models.TenantProfile, text string) string {
+	choice, err := strconv.Atoi(strings.TrimSpace(text))
+	if err != nil || choice < 1 || choice > len(complaintCategories) {
+		return fmt.Sprintf("Please reply with a number from 1 to %d, or CANCEL.", len(complaintCategories))
+	}
+
+	var room models.Room
+	config.DB.First(&room, profile.RoomID)
+
+	complaint := models.Complaint{
+		PropertyID:  profile.PropertyID,
+		RoomNo:      room.RoomNumber,
+		TenantName:  profile.Name,
+		PhoneNumber: profile.PhoneNumber,
+		Category:    complaintCategories[choice-1],
+		Description: session.Pending,
+	}
+
+	session.State, session.Pending = "idle", ""
+
+	if _, err := RegisterComplaint(complaint); err != nil {
+		if strings.HasPrefix(err.Error(), "rate limit") {
+			return "⏳ You can raise " + strings.TrimPrefix(err.Error(), "rate limit: ") + ". Please try again later."
+		}
+		log.Printf("⚠️ Chatbot complaint failed for %s: %v", profile.Name, err)
+		return "⚠️ Could not register your complaint. Please contact your PG owner."
+	}
+
+	return fmt.Sprintf("✅ Your %s complaint has been registered. We'll update you once it's fixed.", complaint.Category)
+}
+
+// replyToSender answers on the channel the message came in on; chat replies are free text, not templates
+func replyToSender(inbound models.InboundMessage, propertyID, tenantID uint, body string) {
+	msg := notifier.Message{Channel: inbound.Channel, To: inbound.From, Body: body}
+	if _, err := enqueueMessage(msg, propertyID, tenantID, "chatbot_reply"); err != nil {
+		log.Printf("⚠️ Could not queue chatbot reply to %s: %v", inbound.From, err)
+	}
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"log"
+	"pg-manager-backend/storage"
+	"strings"
+	"time"
+)
+
+// generatedPrefixes hold PDFs that can be rebuilt from the database at any time
+var generatedPrefixes = []string{"receipts/", "invoices/"}
+
+// CleanupGeneratedFiles deletes receipt and invoice PDFs older than the retention period.
+// They are regenerated on demand (e.g. by the chatbot's RECEIPT command).
+func CleanupGeneratedFiles(retentionDays int) error {
+	if retentionDays <= 0 {
+		return nil
+	}
+	cutoff := time.Now().AddDate(0, 0, -retentionDays)
+	store := storage.Default()
+
+	removed := 0
+	for _, prefix := range generatedPrefixes {
+		objects, err := store.List(prefix)
+		if err != nil {
+			return err
+		}
+
+		for _, object := range objects {
+			if !strings.HasSuffix(object.Key, ".pdf") || object.ModTime.After(cutoff) {
+				continue
+			}
+			if err := store.Delete(object.Key); err == nil {
+				removed++
+			}
+		}
+	}
+
+	log.Printf("🧹 Cleanup: removed %d generated PDFs older than %d days", removed, retentionDays)
+	return nil
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"pg-manager-backend/storage"
+	"pg-manager-backend/utils"
+	"strings"
+	"time"
+)
+
+// MaxComplaintUploads is how many files one request may carry
+const MaxComplaintUploads = 5
+
+const complaintThumbSide = 320
+
+// ComplaintUpload is one file as received from a multipart form
+type ComplaintUpload struct {
+	FileName string
+	Data     []byte
+}
+
+// ComplaintAttachmentView is an attachment with short-lived links to the file and its thumbnail
+type ComplaintAttachmentView struct {
+	models.ComplaintAttachment
+	URL          string `json:"url"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+}
+
+type complaintMedia struct {
+	upload      ComplaintUpload
+	mediaType   string
+	contentType string
+	ext         string
+}
+
+func init() {
+	// DownloadFile serves by extension; make sure the video and HEIC types resolve everywhere
+	for ext, contentType := range map[string]string{".mp4": "video/mp4", ".mov": "video/quicktime", ".webm": "video/webm", ".heic": "image/heic"} {
+		mime.AddExtensionType(ext, contentType)
+	}
+}
+
+// sniffComplaintMedia identifies a file by its content, never by the name or type the client claims.
+// ISO media files (MP4, MOV, HEIC) share the "ftyp" box, so their brand decides.
+func sniffComplaintMedia(data []byte) (mediaType, contentType, ext string, ok bool) {
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		return "image", "image/jpeg", ".jpg", true
+	case "image/png":
+		return "image", "image/png", ".png", true
+	case "image/gif":
+		return "image", "image/gif", ".gif", true
+	case "video/webm":
+		return "video", "video/webm", ".webm", true
+	}
+
+	if len(data) >= 12 && string(data[4:8]) == "ftyp" {
+		brand := string(data[8:12])
+		switch {
+		case brand == "heic" || brand == "heix" || brand == "mif1" || brand == "msf1":
+			return "image", "image/heic", ".heic", true
+		case brand == "qt  ":
+			return "video", "video/quicktime", ".mov", true
+		case brand == "isom" || brand == "iso2" || brand == "avc1" || strings.HasPrefix(brand, "mp4") || strings.HasPrefix(brand, "3gp"):
+			return "video", "video/mp4", ".mp4", true
+		}
+	}
+	return "", "", "", false
+}
+
+// MaxComplaintFileBytes is the largest file any complaint upload may be
+func MaxComplaintFileBytes() int64 {
+	return int64(max(config.App.ComplaintImageMaxMB, config.App.ComplaintVideoMaxMB)) << 20
+}
+
+// MaxPublicComplaintBytes is the total size of the files one QR-form complaint may carry.
+// Owners may upload MaxComplaintUploads full-size files; strangers on the public form get less.
+func MaxPublicComplaintBytes() int64 {
+	return min(int64(config.App.ComplaintPublicMaxMB)<<20, MaxComplaintUploads*MaxComplaintFileBytes())
+}
+
+// validateComplaintUploads checks count, type and size before anything is saved
+func validateComplaintUploads(uploads []ComplaintUpload) ([]complaintMedia, error) {
+	if len(uploads) > MaxComplaintUploads {
+		return nil, fmt.Errorf("invalid attachments: at most %d files at a time", MaxComplaintUploads)
+	}
+
+	media := make([]complaintMedia, 0, len(uploads))
+	for _, upload := range uploads {
+		mediaType, contentType, ext, ok := sniffComplaintMedia(upload.Data)
+		if !ok {
+			return nil, fmt.Errorf("invalid attachment %s: only JPEG, PNG, GIF, HEIC photos and MP4, MOV, WebM videos are allowed", upload.FileName)
+		}
+
+		limitMB := config.App.ComplaintImageMaxMB
+		if mediaType == "video" {
+			limitMB = config.App.ComplaintVideoMaxMB
+		}
+		if len(upload.Data) > limitMB<<20 {
+			return nil, fmt.Errorf("invalid attachment %s: %ss must be under %d MB", upload.FileName, mediaType, limitMB)
+		}
+
+		media = append(media, complaintMedia{upload: upload, mediaType: mediaType, contentType: contentType, ext: ext})
+	}
+	return media, nil
+}
+
+// storeComplaintMedia saves validated files and their thumbnails, then records them. A file that cannot be
+// stored is logged and skipped so the rest still land; a thumbnail that cannot be made is simply left out.
+func storeComplaintMedia(complaintID uint, stage string, uploadedBy uint, media []complaintMedia) []models.ComplaintAttachment {
+	store := storage.Default()
+	var saved []models.ComplaintAttachment
+
+	for i, m := range media {
+		base := fmt.Sprintf("complaints/%d/%s_%d_%d", complaintID, stage, time.Now().UnixNano(), i)
+		attachment := models.ComplaintAttachment{
+			ComplaintID: complaintID,
+			Stage:       stage,
+			MediaType:   m.mediaType,
+			ContentType: m.contentType,
+			FileKey:     base + m.ext,
+			Size:        int64(len(m.upload.Data)),
+			UploadedBy:  uploadedBy,
+		}
+
+		if err := store.Put(attachment.FileKey, m.upload.Data, m.contentType); err != nil {
+			log.Printf("⚠️ Could not store %s for complaint #%d: %v", m.upload.FileName, complaintID, err)
+			continue
+		}
+
+		if m.mediaType == "image" && m.contentType != "image/heic" {
+			if thumb, err := utils.Thumbnail(m.upload.Data, complaintThumbSide); err == nil {
+				thumbKey := base + "_thumb.jpg"
+				if err := store.Put(thumbKey, thumb, "image/jpeg"); err == nil {
+					attachment.ThumbKey = thumbKey
+				}
+			} else {
+				log.Printf("⚠️ No thumbnail for %s on complaint #%d: %v", m.upload.FileName, complaintID, err)
+			}
+		}
+
+		if err := config.DB.Create(&attachment).Error; err != nil {
+			log.Printf("⚠️ Could not record attachment for complaint #%d: %v", complaintID, err)
+			store.Delete(attachment.FileKey)
+			if attachment.ThumbKey != "" {
+				store.Delete(attachment.ThumbKey)
+			}
+			continue
+		}
+		saved = append(saved, attachment)
+	}
+	return saved
+}
+
+func attachmentNote(stage string, count int) string {
+	label := "photo/video"
+	if count > 1 {
+		label = "photos/videos"
+	}
+	return fmt.Sprintf("Added %d %s %s", count, stage, label)
+}
+
+// AddComplaintAttachments lets the owner add photos or videos to a complaint, e.g. "after" shots of the repair
+func AddComplaintAttachments(complaintID, ownerID uint, stage string, uploads []ComplaintUpload) ([]ComplaintAttachmentView, error) {
+	complaint, err := loadOwnedComplaint(complaintID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if stage != "before" && stage != "after" {
+		return nil, errors.New("invalid stage: use before or after")
+	}
+	if len(uploads) == 0 {
+		return nil, errors.New("invalid attachments: no files uploaded")
+	}
+	media, err := validateComplaintUploads(uploads)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := storeComplaintMedia(complaint.ID, stage, ownerID, media)
+	if len(saved) == 0 {
+		return nil, errors.New("could not save attachments")
+	}
+	config.DB.Create(&models.ComplaintEvent{
+		ComplaintID: complaint.ID,
+		Action:      "attachment",
+		Comment:     attachmentNote(stage, len(saved)),
+		ActorID:     ownerID,
+		ActorName:   actorName(ownerID),
+	})
+
+	return attachmentViews(saved), nil
+}
+
+// ResolveComplaint marks a complaint resolved, optionally with "after" photos as proof of the fix
+func ResolveComplaint(complaintID, ownerID uint, comment string, uploads []ComplaintUpload) (models.Complaint, error) {
+	media, err := validateComplaintUploads(uploads)
+	if err != nil {
+		return models.Complaint{}, err
+	}
+
+	complaint, err := UpdateComplaintStatus(complaintID, ownerID, "resolved", comment)
+	if err != nil {
+		return complaint, err
+	}
+
+	if len(media) > 0 {
+		if saved := storeComplaintMedia(complaint.ID, "after", ownerID, media); len(saved) > 0 {
+			config.DB.Create(&models.ComplaintEvent{
+				ComplaintID: complaint.ID,
+				Action:      "attachment",
+				Comment:     attachmentNote("after", len(saved)),
+				ActorID:     ownerID,
+				ActorName:   actorName(ownerID),
+			})
+		}
+	}
+	return complaint, nil
+}
+
+// complaintAttachments lists a complaint's media, tenant photos first
+func complaintAttachments(complaintID uint) []ComplaintAttachmentView {
+	var attachments []models.ComplaintAttachment
+	config.DB.Where("complaint_id = ?", complaintID).Order("stage desc, created_at asc, id asc").Find(&attachments)
+	return attachmentViews(attachments)
+}
+
+func attachmentViews(attachments []models.ComplaintAttachment) []ComplaintAttachmentView {
+	views := make([]ComplaintAttachmentView, 0, len(attachments))
+	for _, attachment := range attachments {
+		view := ComplaintAttachmentView{ComplaintAttachment: attachment, URL: downloadURL(attachment.FileKey)}
+		if attachment.ThumbKey != "" {
+			view.ThumbnailURL = downloadURL(attachment.ThumbKey)
+		}
+		views = append(views, view)
+	}
+	return views
+}
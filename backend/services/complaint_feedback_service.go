@@ -0,0 +1,324 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// complaintSignature proves a feedback link was issued by us for this complaint, so IDs can't be guessed
+func complaintSignature(complaintID uint) string {
+	mac := hmac.New(sha256.New, []byte(config.App.JWTSecret))
+	mac.Write([]byte("complaint:" + strconv.FormatUint(uint64(complaintID), 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:12])
+}
+
+// complaintFeedbackLink is the page the tenant rates or reopens a complaint from
+func complaintFeedbackLink(complaintID uint) string {
+	return fmt.Sprintf("%s/feedback/%d?sig=%s", config.App.FrontendURL, complaintID, complaintSignature(complaintID))
+}
+
+// complaintTenant finds the profile of whoever raised a complaint; old complaints only have the phone number
+func complaintTenant(complaint models.Complaint) (models.TenantProfile, error) {
+	var profile models.TenantProfile
+	query := config.DB.Where("user_id = ?", complaint.TenantID)
+	if complaint.TenantID == 0 {
+		query = config.DB.Where("phone_number = ? AND property_id = ?", complaint.PhoneNumber, complaint.PropertyID)
+	}
+	err := query.First(&profile).Error
+	return profile, err
+}
+
+// notifyComplaintResolved sends the tenant the "complaint_resolved" message with their rating/reopen link
+func notifyComplaintResolved(complaint models.Complaint) {
+	profile, err := complaintTenant(complaint)
+	if err != nil {
+		log.Printf("⚠️ No tenant to notify for resolved complaint #%d", complaint.ID)
+		return
+	}
+
+	data := map[string]interface{}{
+		"Category":    complaint.Category,
+		"ComplaintID": complaint.ID,
+		"RatingLink":  complaintFeedbackLink(complaint.ID),
+		"ReopenDays":  config.App.ComplaintReopenDays,
+	}
+	if err := notifyTenant(profile, "complaint_resolved", data); err != nil {
+		log.Printf("⚠️ Could not notify %s about resolved complaint #%d: %v", profile.Name, complaint.ID, err)
+	}
+}
+
+// ComplaintFeedback is what the public feedback page shows
+type ComplaintFeedback struct {
+	ComplaintID   uint       `json:"complaint_id"`
+	PropertyName  string     `json:"property_name"`
+	Category      string     `json:"category"`
+	Description   string     `json:"description"`
+	Status        string     `json:"status"`
+	ResolvedAt    *time.Time `json:"resolved_at"`
+	Rating        *int       `json:"rating"`
+	RatingComment string     `json:"rating_comment"`
+	CanRate       bool       `json:"can_rate"`
+	CanReopen     bool       `json:"can_reopen"`
+	ReopenUntil   *time.Time `json:"reopen_until,omitempty"`
+}
+
+// loadSignedComplaint checks a feedback link's signature and loads its complaint
+func loadSignedComplaint(complaintID uint, signature string) (models.Complaint, error) {
+	var complaint models.Complaint
+	if !hmac.Equal([]byte(signature), []byte(complaintSignature(complaintID))) {
+		return complaint, errors.New("invalid link")
+	}
+	if err := config.DB.First(&complaint, complaintID).Error; err != nil {
+		return complaint, errors.New("complaint not found")
+	}
+	return complaint, nil
+}
+
+// reopenDeadline is the last moment a resolved complaint can still be reopened by the tenant
+func reopenDeadline(complaint models.Complaint) *time.Time {
+	if complaint.Status != "resolved" || complaint.ResolvedAt == nil || config.App.ComplaintReopenDays <= 0 {
+		return nil
+	}
+	until := complaint.ResolvedAt.AddDate(0, 0, config.App.ComplaintReopenDays)
+	return &until
+}
+
+func complaintFeedbackView(complaint models.Complaint) ComplaintFeedback {
+	view := ComplaintFeedback{
+		ComplaintID:   complaint.ID,
+		PropertyName:  loadProperty(complaint.PropertyID).Name,
+		Category:      complaint.Category,
+		Description:   complaint.Description,
+		Status:        complaint.Status,
+		ResolvedAt:    complaint.ResolvedAt,
+		Rating:        complaint.Rating,
+		RatingComment: complaint.RatingComment,
+		CanRate:       complaint.Status == "resolved" || complaint.Status == "closed",
+	}
+	if until := reopenDeadline(complaint); until != nil && time.Now().Before(*until) {
+		view.CanReopen = true
+		view.ReopenUntil = until
+	}
+	return view
+}
+
+// GetComplaintFeedback returns the complaint behind a feedback link
+func GetComplaintFeedback(complaintID uint, signature string) (ComplaintFeedback, error) {
+	complaint, err := loadSignedComplaint(complaintID, signature)
+	if err != nil {
+		return ComplaintFeedback{}, err
+	}
+	return complaintFeedbackView(complaint), nil
+}
+
+// RateComplaint records the tenant's 1–5 star rating of a fix; rating again replaces the earlier one
+func RateComplaint(complaintID uint, signature string, rating int, comment string) (ComplaintFeedback, error) {
+	complaint, err := loadSignedComplaint(complaintID, signature)
+	if err != nil {
+		return ComplaintFeedback{}, err
+	}
+	if complaint.Status != "resolved" && complaint.Status != "closed" {
+		return ComplaintFeedback{}, errors.New("cannot rate: complaint is not resolved yet")
+	}
+	if rating < 1 || rating > 5 {
+		return ComplaintFeedback{}, errors.New("invalid rating: use 1 to 5 stars")
+	}
+	comment = strings.TrimSpace(comment)
+	if len(comment) > 500 {
+		return ComplaintFeedback{}, errors.New("invalid comment: at most 500 characters")
+	}
+
+	now := time.Now()
+	note := fmt.Sprintf("Rated %d/5", rating)
+	if comment != "" {
+		note += ": " + comment
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&complaint).Updates(map[string]interface{}{
+			"rating":         rating,
+			"rating_comment": comment,
+			"rated_at":       now,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.ComplaintEvent{
+			ComplaintID: complaint.ID,
+			Action:      "rating",
+			Comment:     note,
+			ActorID:     complaint.TenantID,
+			ActorName:   complaint.TenantName,
+		}).Error
+	})
+	if err != nil {
+		return ComplaintFeedback{}, err
+	}
+
+	config.DB.First(&complaint, complaintID)
+	return complaintFeedbackView(complaint), nil
+}
+
+// ReopenComplaint lets the tenant reopen a resolved complaint within the reopen window. The earlier
+// rating is cleared so the next resolution gets rated on its own.
+func ReopenComplaint(complaintID uint, signature, reason string) (ComplaintFeedback, error) {
+	complaint, err := loadSignedComplaint(complaintID, signature)
+	if err != nil {
+		return ComplaintFeedback{}, err
+	}
+	until := reopenDeadline(complaint)
+	if until == nil || time.Now().After(*until) {
+		return ComplaintFeedback{}, errors.New("cannot reopen: the reopen window has passed, please raise a new complaint")
+	}
+
+	reason = strings.TrimSpace(reason)
+	if len(reason) > 500 {
+		return ComplaintFeedback{}, errors.New("invalid reason: at most 500 characters")
+	}
+	if err := changeComplaintStatus(&complaint, "reopened", reason, complaint.TenantID, complaint.TenantName); err != nil {
+		return ComplaintFeedback{}, err
+	}
+	config.DB.Model(&complaint).Updates(map[string]interface{}{"rating": nil, "rating_comment": "", "rated_at": nil})
+
+	body := fmt.Sprintf("🔁 %s reopened %s complaint #%d (Room %s)", complaint.TenantName, complaint.Category, complaint.ID, complaint.RoomNo)
+	if reason != "" {
+		body += ":\n" + reason
+	}
+	alertOwner(complaint.PropertyID, AlertNewComplaint, "Complaint Reopened", body)
+
+	config.DB.First(&complaint, complaintID)
+	return complaintFeedbackView(complaint), nil
+}
+
+// ComplaintStats is resolution time and satisfaction for a group of complaints
+type ComplaintStats struct {
+	Category          string  `json:"category,omitempty"`
+	Raised            int     `json:"raised"`
+	Resolved          int     `json:"resolved"`
+	AvgResolveHours   float64 `json:"avg_resolve_hours"`
+	ResolvedWithinSLA int     `json:"resolved_within_sla"`
+	Reopened          int     `json:"reopened"`
+	Rated             int     `json:"rated"`
+	RepairCost        float64 `json:"repair_cost"` // Expenses booked against these complaints
+	AvgRating         float64 `json:"avg_rating"`
+
+	resolveHours, ratingSum float64
+}
+
+func (s *ComplaintStats) add(complaint models.Complaint, reopened bool, cost float64) {
+	s.Raised++
+	s.RepairCost = roundPaise(s.RepairCost + cost)
+	if reopened {
+		s.Reopened++
+	}
+	if complaint.ResolvedAt != nil && (complaint.Status == "resolved" || complaint.Status == "closed") {
+		s.Resolved++
+		s.resolveHours += complaint.ResolvedAt.Sub(complaint.CreatedAt).Hours()
+		if complaint.DueAt != nil && !complaint.ResolvedAt.After(*complaint.DueAt) {
+			s.ResolvedWithinSLA++
+		}
+	}
+	if complaint.Rating != nil {
+		s.Rated++
+		s.ratingSum += float64(*complaint.Rating)
+	}
+}
+
+func (s *ComplaintStats) finish() {
+	if s.Resolved > 0 {
+		s.AvgResolveHours = math.Round(s.resolveHours/float64(s.Resolved)*10) / 10
+	}
+	if s.Rated > 0 {
+		s.AvgRating = math.Round(s.ratingSum/float64(s.Rated)*100) / 100
+	}
+}
+
+// PropertyComplaintReport is one property's totals with a breakdown per category
+type PropertyComplaintReport struct {
+	PropertyID   uint             `json:"property_id"`
+	PropertyName string           `json:"property_name"`
+	Total        ComplaintStats   `json:"total"`
+	Categories   []ComplaintStats `json:"categories"`
+}
+
+// GetComplaintReport summarises complaints raised between from and to (inclusive) for one property,
+// or every property of the owner when propertyID is 0
+func GetComplaintReport(ownerID, propertyID uint, from, to time.Time) ([]PropertyComplaintReport, error) {
+	var properties []models.Property
+	if propertyID != 0 {
+		if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+			return nil, err
+		}
+		properties = []models.Property{loadProperty(propertyID)}
+	} else {
+		properties, _ = GetAllProperties(ownerID)
+	}
+
+	start, end := dateOnly(from), dateOnly(to).AddDate(0, 0, 1)
+	if !end.After(start) {
+		return nil, errors.New("invalid period: from must be on or before to")
+	}
+
+	reports := make([]PropertyComplaintReport, 0, len(properties))
+	for _, property := range properties {
+		var complaints []models.Complaint
+		config.DB.Where("property_id = ? AND created_at >= ? AND created_at < ?", property.ID, start, end).Find(&complaints)
+
+		var reopenedIDs []uint
+		config.DB.Model(&models.ComplaintEvent{}).
+			Joins("JOIN complaints ON complaints.id = complaint_events.complaint_id").
+			Where("complaints.property_id = ? AND complaints.created_at >= ? AND complaints.created_at < ? AND complaint_events.to_status = ?",
+				property.ID, start, end, "reopened").
+			Distinct().Pluck("complaint_events.complaint_id", &reopenedIDs)
+		var costRows []struct {
+			ComplaintID uint
+			Total       float64
+		}
+		config.DB.Model(&models.Expenditure{}).
+			Select("complaint_id, SUM(amount) AS total").
+			Where("property_id = ? AND complaint_id IS NOT NULL", property.ID).
+			Group("complaint_id").Scan(&costRows)
+		costs := map[uint]float64{}
+		for _, row := range costRows {
+			costs[row.ComplaintID] = row.Total
+		}
+
+		reopened := map[uint]bool{}
+		for _, id := range reopenedIDs {
+			reopened[id] = true
+		}
+
+		report := PropertyComplaintReport{PropertyID: property.ID, PropertyName: property.Name, Categories: []ComplaintStats{}}
+		byCategory := map[string]*ComplaintStats{}
+		for _, complaint := range complaints {
+			stats, ok := byCategory[complaint.Category]
+			if !ok {
+				stats = &ComplaintStats{Category: complaint.Category}
+				byCategory[complaint.Category] = stats
+			}
+			stats.add(complaint, reopened[complaint.ID], costs[complaint.ID])
+			report.Total.add(complaint, reopened[complaint.ID], costs[complaint.ID])
+		}
+
+		report.Total.finish()
+		for _, stats := range byCategory {
+			stats.finish()
+			report.Categories = append(report.Categories, *stats)
+		}
+		sort.Slice(report.Categories, func(i, j int) bool { return report.Categories[i].Category < report.Categories[j].Category })
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
@@ -2,40 +2,524 @@ package services
 
 import (
 	"errors"
+	"fmt"
+	"log"
 	"pg-manager-backend/config"
 	"pg-manager-backend/models"
+	"pg-manager-backend/notifier"
+	"pg-manager-backend/utils"
+	"sort"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// RegisterComplaint saves the data from the QR form
-func RegisterComplaint(data models.Complaint) error {
-	var count int64
+// Statuses that still need work; everything else is resolved or closed
+var openComplaintStatuses = []string{"open", "acknowledged", "in_progress", "on_hold", "reopened"}
 
-	// 1. Check if the phone number belongs to an active tenant in THIS property
-	config.DB.Model(&models.TenantProfile{}).
-		Where("phone_number = ? AND property_id = ?", data.PhoneNumber, data.PropertyID).
-		Count(&count)
+// complaintTransitions lists where each status may move next
+var complaintTransitions = map[string][]string{
+	"open":         {"acknowledged", "in_progress", "on_hold", "resolved", "closed"},
+	"acknowledged": {"in_progress", "on_hold", "resolved", "closed"},
+	"in_progress":  {"on_hold", "resolved"},
+	"on_hold":      {"in_progress", "resolved", "closed"},
+	"resolved":     {"reopened", "closed"},
+	"reopened":     {"acknowledged", "in_progress", "on_hold", "resolved"},
+	"closed":       {},
+}
+
+var complaintPriorities = []string{"low", "medium", "high", "urgent"}
+
+// defaultComplaintSLAHours is the resolution time per category unless the property overrides it
+var defaultComplaintSLAHours = map[string]int{
+	"Plumbing":   24,
+	"Electrical": 12,
+	"Cleaning":   12,
+	"WiFi":       24,
+	"Food":       8,
+	"Other":      48,
+}
+
+// ComplaintView is a complaint as the dashboard shows it, with its SLA state worked out
+type ComplaintView struct {
+	models.Complaint
+	Overdue      bool `json:"overdue"`
+	HoursOverdue int  `json:"hours_overdue"`
+}
+
+// ComplaintDetail is a complaint with its full history, oldest first, its photos/videos and what fixing it cost
+type ComplaintDetail struct {
+	ComplaintView
+	History     []models.ComplaintEvent   `json:"history"`
+	Attachments []ComplaintAttachmentView `json:"attachments"`
+	WorkOrders  []models.WorkOrder        `json:"work_orders"`
+	Expenses    []models.Expenditure      `json:"expenses"`
+	TotalCost   float64                   `json:"total_cost"`
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func complaintView(complaint models.Complaint, now time.Time) ComplaintView {
+	view := ComplaintView{Complaint: complaint}
+	if complaint.DueAt != nil && now.After(*complaint.DueAt) && containsString(openComplaintStatuses, complaint.Status) {
+		view.Overdue = true
+		view.HoursOverdue = int(now.Sub(*complaint.DueAt).Hours())
+	}
+	return view
+}
+
+// complaintSLAHours is the property's resolution time for a category, falling back to the defaults
+func complaintSLAHours(propertyID uint, category string) int {
+	var sla models.ComplaintSLA
+	if err := config.DB.Where("property_id = ? AND category = ?", propertyID, category).First(&sla).Error; err == nil && sla.ResolveHours > 0 {
+		return sla.ResolveHours
+	}
+	if hours, ok := defaultComplaintSLAHours[category]; ok {
+		return hours
+	}
+	return defaultComplaintSLAHours["Other"]
+}
+
+func complaintDeadline(propertyID uint, category string, from time.Time) *time.Time {
+	due := from.Add(time.Duration(complaintSLAHours(propertyID, category)) * time.Hour)
+	return &due
+}
+
+// complaintLimitReached reports whether the tenant has used up their complaints for the last 24 hours
+func complaintLimitReached(db *gorm.DB, tenantID uint) bool {
+	if config.App.ComplaintsPerDay <= 0 {
+		return false
+	}
+	var recent int64
+	db.Model(&models.Complaint{}).
+		Where("tenant_id = ? AND created_at > ?", tenantID, time.Now().Add(-24*time.Hour)).
+		Count(&recent)
+	return recent >= int64(config.App.ComplaintsPerDay)
+}
+
+func complaintLimitError() error {
+	if config.App.ComplaintsPerDay == 1 {
+		return errors.New("rate limit: only one complaint per 24 hours")
+	}
+	return fmt.Errorf("rate limit: at most %d complaints per 24 hours", config.App.ComplaintsPerDay)
+}
+
+// verifyComplainant finds the active tenant of the complaint's property whose phone number it carries.
+// A room, when given, must be that tenant's own.
+func verifyComplainant(data models.Complaint) (models.TenantProfile, error) {
+	phone := data.PhoneNumber
+	if normalized, err := utils.NormalizePhone(phone); err == nil {
+		phone = normalized
+	}
 
-	if count == 0 {
+	var profile models.TenantProfile
+	if err := config.DB.Preload("Room").
+		Where("phone_number = ? AND property_id = ? AND status = ?", phone, data.PropertyID, "active").
+		First(&profile).Error; err != nil {
 		// Return a specific error if verification fails
-		return errors.New("tenant_not_verified")
+		return profile, errors.New("tenant_not_verified")
+	}
+	if room := strings.TrimSpace(data.RoomNo); room != "" && !strings.EqualFold(room, profile.Room.RoomNumber) {
+		return profile, errors.New("tenant_not_verified")
+	}
+	return profile, nil
+}
+
+// RegisterComplaint saves a complaint from the QR form or the chatbot, with any photos/videos the tenant sent.
+// The phone number must belong to an active tenant of the property, and when a room number is given it must
+// be that tenant's room.
+func RegisterComplaint(data models.Complaint, uploads ...ComplaintUpload) (models.Complaint, error) {
+	// Numbers are stored in E.164; the form may send them any way the tenant typed them
+	if phone, err := utils.NormalizePhone(data.PhoneNumber); err == nil {
+		data.PhoneNumber = phone
 	}
 
-	// 2. If verified, save the complaint
+	// 1. Check the form itself
+	data.Description = strings.TrimSpace(data.Description)
+	if len(data.Description) < 5 || len(data.Description) > 1000 {
+		return data, errors.New("invalid description: use 5 to 1000 characters")
+	}
+	if !containsString(complaintCategories, data.Category) {
+		return data, fmt.Errorf("invalid category: use one of %s", strings.Join(complaintCategories, ", "))
+	}
+	media, err := validateComplaintUploads(uploads)
+	if err != nil {
+		return data, err
+	}
+
+	// 2. Check the phone number belongs to an active tenant in THIS property, living in the room given
+	profile, err := verifyComplainant(data)
+	if err != nil {
+		return data, err
+	}
+
+	// 3. Link it to the tenant; name and room are kept as they are today for the history
+	data.TenantID = profile.UserID
+	data.RoomID = profile.RoomID
+	data.TenantName = profile.Name
+	data.RoomNo = profile.Room.RoomNumber
 	data.CreatedAt = time.Now()
-	data.Status = "Pending"
-	return config.DB.Create(&data).Error
+	data.Status = "open"
+	if !containsString(complaintPriorities, data.Priority) {
+		data.Priority = "medium"
+	}
+	data.DueAt = complaintDeadline(data.PropertyID, data.Category, data.CreatedAt)
+
+	// 4. Save with the first history entry; the profile lock stops two submissions slipping past the limit
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&profile, profile.ID).Error; err != nil {
+			return err
+		}
+		if complaintLimitReached(tx, profile.UserID) {
+			return complaintLimitError()
+		}
+		if err := tx.Create(&data).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&profile).Update("last_complaint_date", data.CreatedAt).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.ComplaintEvent{
+			ComplaintID: data.ID,
+			Action:      "created",
+			ToStatus:    "open",
+			Comment:     data.Description,
+			ActorID:     profile.UserID,
+			ActorName:   data.TenantName,
+		}).Error
+	})
+	if err != nil {
+		return data, err
+	}
+
+	// 5. Photos/videos are stored once the complaint exists; a file that fails to store does not undo it
+	note := ""
+	if len(media) > 0 {
+		if saved := storeComplaintMedia(data.ID, "before", profile.UserID, media); len(saved) > 0 {
+			note = fmt.Sprintf("\n📎 %d photo/video attached", len(saved))
+		}
+	}
+
+	// 6. Let the owner know without waiting for a dashboard visit
+	alertOwner(data.PropertyID, AlertNewComplaint, "New Complaint",
+		fmt.Sprintf("🛠️ New %s complaint #%d from %s (Room %s):\n%s%s", data.Category, data.ID, data.TenantName, data.RoomNo, data.Description, note))
+	return data, nil
 }
 
-// GetAllComplaints returns complaints for the owner's dashboard
-func GetAllComplaints(propertyID string) ([]models.Complaint, error) {
+// GetAllComplaints returns a property's complaints for the owner's dashboard: SLA breaches first, then newest.
+// status may be a single status or "active" for everything still open.
+func GetAllComplaints(propertyID, ownerID uint, status string, overdueOnly bool) ([]ComplaintView, error) {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return nil, err
+	}
+
+	query := config.DB.Where("property_id = ?", propertyID)
+	switch {
+	case status == "active":
+		query = query.Where("status IN ?", openComplaintStatuses)
+	case status != "":
+		query = query.Where("status = ?", status)
+	}
+
 	var complaints []models.Complaint
-	// Order by CreatedAt desc so the owner sees new issues first
-	err := config.DB.Where("property_id = ?", propertyID).Order("created_at desc").Find(&complaints).Error
-	return complaints, err
+	if err := query.Order("created_at desc").Find(&complaints).Error; err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	views := make([]ComplaintView, 0, len(complaints))
+	for _, complaint := range complaints {
+		view := complaintView(complaint, now)
+		if overdueOnly && !view.Overdue {
+			continue
+		}
+		views = append(views, view)
+	}
+
+	sort.SliceStable(views, func(i, j int) bool { return views[i].Overdue && !views[j].Overdue })
+	return views, nil
 }
 
-// ResolveComplaint updates the status in the DB
-func ResolveComplaint(id string) error {
-	return config.DB.Model(&models.Complaint{}).Where("id = ?", id).Update("status", "Resolved").Error
+// loadOwnedComplaint fetches a complaint the owner is allowed to act on
+func loadOwnedComplaint(complaintID, ownerID uint) (models.Complaint, error) {
+	var complaint models.Complaint
+	if err := config.DB.First(&complaint, complaintID).Error; err != nil {
+		return complaint, errors.New("complaint not found")
+	}
+	if err := verifyPropertyOwner(complaint.PropertyID, ownerID); err != nil {
+		return complaint, err
+	}
+	return complaint, nil
+}
+
+func actorName(userID uint) string {
+	var user models.User
+	config.DB.Select("name").First(&user, userID)
+	return user.Name
+}
+
+// GetComplaintDetail returns a complaint with its history
+func GetComplaintDetail(complaintID, ownerID uint) (ComplaintDetail, error) {
+	complaint, err := loadOwnedComplaint(complaintID, ownerID)
+	if err != nil {
+		return ComplaintDetail{}, err
+	}
+
+	detail := ComplaintDetail{ComplaintView: complaintView(complaint, time.Now()), History: []models.ComplaintEvent{}}
+	config.DB.Where("complaint_id = ?", complaintID).Order("created_at asc, id asc").Find(&detail.History)
+	detail.Attachments = complaintAttachments(complaintID)
+	detail.WorkOrders = []models.WorkOrder{}
+	config.DB.Preload("Vendor").Where("complaint_id = ?", complaintID).Order("created_at asc").Find(&detail.WorkOrders)
+	detail.Expenses, detail.TotalCost = complaintCosts(complaintID)
+	return detail, nil
+}
+
+// changeComplaintStatus applies an allowed transition, stamps its timestamp and records the history entry
+func changeComplaintStatus(complaint *models.Complaint, status, comment string, actorID uint, actor string) error {
+	allowed, known := complaintTransitions[complaint.Status]
+	if !known {
+		// Rows from before the lifecycle existed
+		allowed = complaintTransitions["open"]
+	}
+	if !containsString(allowed, status) {
+		return fmt.Errorf("invalid status change: %s → %s", complaint.Status, status)
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"status": status}
+	switch status {
+	case "acknowledged":
+		if complaint.AcknowledgedAt == nil {
+			updates["acknowledged_at"] = now
+		}
+	case "resolved":
+		updates["resolved_at"] = now
+	case "closed":
+		updates["closed_at"] = now
+	case "reopened":
+		// A reopened complaint gets a fresh SLA window
+		updates["resolved_at"] = nil
+		updates["closed_at"] = nil
+		updates["due_at"] = complaintDeadline(complaint.PropertyID, complaint.Category, now)
+	}
+
+	from := complaint.Status
+	return config.DB.Transaction(func(tx *gorm.DB) error {
+		// Only one of two concurrent changes from the same status may win
+		result := tx.Model(&models.Complaint{}).Where("id = ? AND status = ?", complaint.ID, from).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return errors.New("invalid status change: the complaint was updated by someone else, reload and try again")
+		}
+		return tx.Create(&models.ComplaintEvent{
+			ComplaintID: complaint.ID,
+			Action:      "status",
+			FromStatus:  from,
+			ToStatus:    status,
+			Comment:     comment,
+			ActorID:     actorID,
+			ActorName:   actor,
+		}).Error
+	})
+}
+
+// UpdateComplaintStatus moves a complaint through its lifecycle with an optional comment
+func UpdateComplaintStatus(complaintID, ownerID uint, status, comment string) (models.Complaint, error) {
+	complaint, err := loadOwnedComplaint(complaintID, ownerID)
+	if err != nil {
+		return complaint, err
+	}
+	if err := changeComplaintStatus(&complaint, status, strings.TrimSpace(comment), ownerID, actorName(ownerID)); err != nil {
+		return complaint, err
+	}
+	config.DB.First(&complaint, complaintID)
+
+	if status == "resolved" {
+		notifyComplaintResolved(complaint)
+	}
+	return complaint, nil
+}
+
+// AssignComplaint hands a complaint to a staff member or vendor and messages them the details
+func AssignComplaint(complaintID, ownerID uint, assigneeType, name, phone, comment string) (models.Complaint, error) {
+	complaint, err := loadOwnedComplaint(complaintID, ownerID)
+	if err != nil {
+		return complaint, err
+	}
+	if assigneeType != "staff" && assigneeType != "vendor" {
+		return complaint, errors.New("invalid assignee type: use staff or vendor")
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return complaint, errors.New("invalid assignee: name is required")
+	}
+	if phone = strings.TrimSpace(phone); phone != "" {
+		normalized, err := utils.NormalizePhone(phone)
+		if err != nil {
+			return complaint, errors.New("invalid assignee phone")
+		}
+		phone = normalized
+	}
+
+	if err := assignComplaint(config.DB, &complaint, ownerID, assigneeType, name, phone, comment); err != nil {
+		return complaint, err
+	}
+
+	if phone != "" {
+		body := fmt.Sprintf("🛠️ Complaint #%d assigned to you\nRoom %s — %s\n%s", complaint.ID, complaint.RoomNo, complaint.Category, complaint.Description)
+		if complaint.DueAt != nil {
+			body += "\nPlease fix by " + complaint.DueAt.Format("02 Jan 3:04 PM")
+		}
+		if _, err := enqueueMessage(notifier.Message{Channel: notifier.WhatsApp, To: phone, Body: body},
+			complaint.PropertyID, 0, "complaint_assigned"); err != nil {
+			log.Printf("⚠️ Could not notify assignee for complaint #%d: %v", complaint.ID, err)
+		}
+	}
+
+	return complaint, nil
+}
+
+// assignComplaint records the assignee and the history entry; callers validate and notify
+func assignComplaint(db *gorm.DB, complaint *models.Complaint, ownerID uint, assigneeType, name, phone, comment string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(complaint).Updates(map[string]interface{}{
+			"assignee_type":  assigneeType,
+			"assignee_name":  name,
+			"assignee_phone": phone,
+		}).Error; err != nil {
+			return err
+		}
+		note := fmt.Sprintf("Assigned to %s (%s)", name, assigneeType)
+		if comment = strings.TrimSpace(comment); comment != "" {
+			note += ": " + comment
+		}
+		return tx.Create(&models.ComplaintEvent{
+			ComplaintID: complaint.ID,
+			Action:      "assigned",
+			Comment:     note,
+			ActorID:     ownerID,
+			ActorName:   actorName(ownerID),
+		}).Error
+	})
+}
+
+// UpdateComplaintPriority changes a complaint's priority
+func UpdateComplaintPriority(complaintID, ownerID uint, priority, comment string) (models.Complaint, error) {
+	complaint, err := loadOwnedComplaint(complaintID, ownerID)
+	if err != nil {
+		return complaint, err
+	}
+	if !containsString(complaintPriorities, priority) {
+		return complaint, errors.New("invalid priority: use low, medium, high or urgent")
+	}
+	if priority == complaint.Priority {
+		return complaint, nil
+	}
+
+	note := fmt.Sprintf("Priority %s → %s", complaint.Priority, priority)
+	if comment = strings.TrimSpace(comment); comment != "" {
+		note += ": " + comment
+	}
+
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&complaint).Update("priority", priority).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.ComplaintEvent{
+			ComplaintID: complaint.ID,
+			Action:      "priority",
+			Comment:     note,
+			ActorID:     ownerID,
+			ActorName:   actorName(ownerID),
+		}).Error
+	})
+	return complaint, err
+}
+
+// AddComplaintComment adds a note to the complaint's history without changing it
+func AddComplaintComment(complaintID, ownerID uint, comment string) (models.ComplaintEvent, error) {
+	if _, err := loadOwnedComplaint(complaintID, ownerID); err != nil {
+		return models.ComplaintEvent{}, err
+	}
+	comment = strings.TrimSpace(comment)
+	if comment == "" {
+		return models.ComplaintEvent{}, errors.New("invalid comment: text is required")
+	}
+
+	event := models.ComplaintEvent{
+		ComplaintID: complaintID,
+		Action:      "comment",
+		Comment:     comment,
+		ActorID:     ownerID,
+		ActorName:   actorName(ownerID),
+	}
+	err := config.DB.Create(&event).Error
+	return event, err
+}
+
+// GetComplaintSLAs returns the resolution hours per category in effect for a property
+func GetComplaintSLAs(propertyID, ownerID uint) (map[string]int, error) {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return nil, err
+	}
+
+	hours := map[string]int{}
+	for category, h := range defaultComplaintSLAHours {
+		hours[category] = h
+	}
+	var overrides []models.ComplaintSLA
+	config.DB.Where("property_id = ?", propertyID).Find(&overrides)
+	for _, sla := range overrides {
+		hours[sla.Category] = sla.ResolveHours
+	}
+	return hours, nil
+}
+
+// UpdateComplaintSLAs sets resolution hours per category; existing complaints keep their deadline
+func UpdateComplaintSLAs(propertyID, ownerID uint, hours map[string]int) (map[string]int, error) {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return nil, err
+	}
+	for category, h := range hours {
+		if _, ok := defaultComplaintSLAHours[category]; !ok {
+			return nil, fmt.Errorf("invalid category: %s", category)
+		}
+		if h < 1 || h > 24*30 {
+			return nil, fmt.Errorf("invalid hours for %s: use 1 to 720", category)
+		}
+	}
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		for category, h := range hours {
+			sla := models.ComplaintSLA{PropertyID: propertyID, Category: category}
+			if err := tx.Where(sla).Assign(models.ComplaintSLA{ResolveHours: h}).FirstOrCreate(&sla).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return GetComplaintSLAs(propertyID, ownerID)
+}
+
+// countOverdueComplaints is the number of unresolved complaints past their SLA deadline
+func countOverdueComplaints(propertyID uint) int64 {
+	var count int64
+	config.DB.Model(&models.Complaint{}).
+		Where("property_id = ? AND status IN ? AND due_at < ?", propertyID, openComplaintStatuses, time.Now()).
+		Count(&count)
+	return count
 }
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"testing"
+)
+
+func TestStaleStatusChangeIsRejected(t *testing.T) {
+	requireDB(t)
+	property, tenant := createTestTenant(t, models.TenantProfile{Name: "Ravi"})
+	complaint := models.Complaint{PropertyID: property.ID, TenantID: tenant.UserID, TenantName: tenant.Name,
+		PhoneNumber: tenant.PhoneNumber, Category: "Plumbing", Description: "Tap leaking", Status: "open"}
+	config.DB.Create(&complaint)
+
+	// Two people opened the complaint while it was still open
+	first, second := complaint, complaint
+	if err := changeComplaintStatus(&first, "resolved", "", property.OwnerID, "Owner"); err != nil {
+		t.Fatalf("first change: %v", err)
+	}
+	if err := changeComplaintStatus(&second, "acknowledged", "", property.OwnerID, "Owner"); err == nil {
+		t.Fatal("second change from the stale status was accepted")
+	}
+
+	var stored models.Complaint
+	config.DB.First(&stored, complaint.ID)
+	var events int64
+	config.DB.Model(&models.ComplaintEvent{}).Where("complaint_id = ?", complaint.ID).Count(&events)
+	if stored.Status != "resolved" || events != 1 {
+		t.Errorf("status %s with %d history entries, want resolved with 1", stored.Status, events)
+	}
+}
+
+func TestQRComplaintIsCheckedBeforeUploads(t *testing.T) {
+	requireDB(t)
+	property, tenant := createTestTenant(t, models.TenantProfile{Name: "Ravi"})
+	token := qrToken(qrFormComplaint, property, 0)
+
+	cases := []struct {
+		name, token, phone, room, want string
+	}{
+		{"tenant", token, tenant.PhoneNumber, "101", ""},
+		{"stranger", token, "+919900112233", "101", "tenant_not_verified"},
+		{"wrong room", token, tenant.PhoneNumber, "102", "tenant_not_verified"},
+		{"forged token", token + "x", tenant.PhoneNumber, "101", "invalid qr code"},
+	}
+	for _, tc := range cases {
+		data, err := CheckQRComplaint(tc.token, models.Complaint{PhoneNumber: tc.phone, RoomNo: tc.room})
+		switch {
+		case tc.want == "" && (err != nil || data.PropertyID != property.ID):
+			t.Errorf("%s: property %d, err %v; want property %d", tc.name, data.PropertyID, err, property.ID)
+		case tc.want != "" && (err == nil || err.Error() != tc.want):
+			t.Errorf("%s: err %v, want %s", tc.name, err, tc.want)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"errors"
 	"pg-manager-backend/config"
 	"pg-manager-backend/models"
 	"time"
@@ -10,6 +11,16 @@ func RecordExpense(expense models.Expenditure) error {
 	if expense.Date.IsZero() {
 		expense.Date = time.Now()
 	}
+
+	// A cost logged against a complaint must be for the same property
+	if expense.ComplaintID != nil {
+		var complaint models.Complaint
+		if err := config.DB.First(&complaint, *expense.ComplaintID).Error; err != nil || complaint.PropertyID != expense.PropertyID {
+			return errors.New("invalid complaint for this property")
+		}
+	}
+	// Work orders book their own expense when completed
+	expense.WorkOrderID = nil
 	return config.DB.Create(&expense).Error
 }
 
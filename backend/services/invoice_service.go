@@ -0,0 +1,203 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"pg-manager-backend/storage"
+	"pg-manager-backend/utils"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OpenInvoiceResponse is an unpaid invoice with enough tenant context for the reconciliation screen
+type OpenInvoiceResponse struct {
+	models.Invoice
+	AmountDue   float64 `json:"amount_due"`
+	TenantName  string  `json:"tenant_name"`
+	RoomNo      string  `json:"room_no"`
+	PhoneNumber string  `json:"phone_number"`
+}
+
+// createInvoice raises a new open invoice inside the caller's transaction
+func createInvoice(tx *gorm.DB, profile models.TenantProfile, amount float64, description string, dueDate time.Time) (models.Invoice, error) {
+	number, err := nextDocumentNumber(tx, profile.PropertyID, "invoice", time.Now())
+	if err != nil {
+		return models.Invoice{}, err
+	}
+
+	invoice := models.Invoice{
+		Number:      number,
+		PropertyID:  profile.PropertyID,
+		TenantID:    profile.UserID,
+		Reference:   fmt.Sprintf("TMP-%d-%d", profile.UserID, time.Now().UnixNano()),
+		Description: description,
+		Amount:      amount,
+		Status:      "open",
+		DueDate:     dueDate,
+	}
+	if err := tx.Create(&invoice).Error; err != nil {
+		return models.Invoice{}, err
+	}
+
+	// The reference is what goes into the UPI "tr" field, so keep it short and stable
+	invoice.Reference = fmt.Sprintf("INV-%06d", invoice.ID)
+	if err := tx.Model(&invoice).Update("reference", invoice.Reference).Error; err != nil {
+		return models.Invoice{}, err
+	}
+
+	// Advance payments carried forward as credit are used up before anything is asked of the tenant
+	if err := applyCredit(tx, &invoice); err != nil {
+		return models.Invoice{}, err
+	}
+	return invoice, nil
+}
+
+// invoicePaymentDetails renders the invoice PDF and returns its URL plus the UPI intent for tenant messages
+func invoicePaymentDetails(invoice models.Invoice, tenantName string) (string, string) {
+	var property models.Property
+	if err := config.DB.First(&property, invoice.PropertyID).Error; err != nil {
+		log.Printf("⚠️ Property %d not found for invoice %s", invoice.PropertyID, invoice.Reference)
+		return "", ""
+	}
+
+	invoiceURL := ""
+	key := "invoices/invoice_" + strconv.FormatUint(uint64(invoice.ID), 10) + ".pdf"
+	pdf, err := utils.GenerateInvoice(invoice, tenantName, property, propertyLetterhead(property))
+	if err == nil {
+		err = storage.Default().Put(key, pdf, "application/pdf")
+	}
+	if err != nil {
+		log.Printf("⚠️ Invoice Generation Failed: %v", err)
+	} else {
+		invoiceURL = downloadURL(key)
+	}
+
+	upiLink := ""
+	if due := invoice.Amount - invoice.AmountPaid; property.UPIVPA != "" && due >= 0.01 {
+		upiLink = utils.BuildUPIIntent(property.UPIVPA, property.UPIPayeeName, due, invoice.Reference, invoice.Description)
+	}
+	return invoiceURL, upiLink
+}
+
+// GetOpenInvoices lists unpaid invoices for a property, oldest due date first
+func GetOpenInvoices(propertyID, ownerID uint) ([]OpenInvoiceResponse, error) {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return nil, err
+	}
+
+	var results []OpenInvoiceResponse
+	err := config.DB.Table("invoices").
+		Select("invoices.*, invoices.amount - invoices.amount_paid AS amount_due, tenant_profiles.name AS tenant_name, rooms.room_no AS room_no, tenant_profiles.phone_number").
+		Joins("JOIN tenant_profiles ON tenant_profiles.user_id = invoices.tenant_id AND tenant_profiles.deleted_at IS NULL").
+		Joins("LEFT JOIN rooms ON rooms.id = tenant_profiles.room_id").
+		Where("invoices.property_id = ? AND invoices.status IN ?", propertyID, []string{"open", "partial"}).
+		Order("invoices.due_date asc").
+		Scan(&results).Error
+
+	return results, err
+}
+
+// MatchUPIPayment suggests open invoices for a UTR the owner saw in their bank/UPI app
+func MatchUPIPayment(propertyID, ownerID uint, utr string, amount float64) ([]OpenInvoiceResponse, error) {
+	if !utils.IsValidUTR(utr) {
+		return nil, errors.New("invalid UTR: expected 12 digits")
+	}
+	if err := ensureUTRUnused(utr); err != nil {
+		return nil, err
+	}
+
+	open, err := GetOpenInvoices(propertyID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// UPI intents carry the exact invoice amount, so an exact match is the strongest signal
+	matches := []OpenInvoiceResponse{}
+	for _, inv := range open {
+		if math.Abs(inv.AmountDue-amount) < 0.01 {
+			matches = append(matches, inv)
+		}
+	}
+	return matches, nil
+}
+
+// ReconcileUPIPayment records a UPI transfer against an open invoice, keyed by its UTR
+func ReconcileUPIPayment(ownerID, invoiceID uint, utr string, amount float64) (models.Payment, float64, error) {
+	if !utils.IsValidUTR(utr) {
+		return models.Payment{}, 0, errors.New("invalid UTR: expected 12 digits")
+	}
+	if amount <= 0 {
+		return models.Payment{}, 0, errors.New("amount must be greater than zero")
+	}
+
+	var invoice models.Invoice
+	if err := config.DB.First(&invoice, invoiceID).Error; err != nil {
+		return models.Payment{}, 0, errors.New("invoice not found")
+	}
+	if err := verifyPropertyOwner(invoice.PropertyID, ownerID); err != nil {
+		return models.Payment{}, 0, err
+	}
+	if invoice.Status == "paid" {
+		return models.Payment{}, 0, errors.New("invoice is already paid")
+	}
+	if err := ensureUTRUnused(utr); err != nil {
+		return models.Payment{}, 0, err
+	}
+
+	tx := config.DB.Begin()
+
+	var profile models.TenantProfile
+	if err := tx.First(&profile, "user_id = ?", invoice.TenantID).Error; err != nil {
+		tx.Rollback()
+		return models.Payment{}, 0, errors.New("tenant profile not found")
+	}
+
+	paymentRecord := models.Payment{
+		Amount:      amount,
+		PaymentType: "Rent-Payment",
+		Method:      "UPI",
+		Reference:   utr,
+		InvoiceID:   &invoice.ID,
+	}
+
+	newBalance, err := applyPayment(tx, &profile, &paymentRecord, []uint{invoice.ID})
+	if err != nil {
+		tx.Rollback()
+		return models.Payment{}, 0, utrConflict(err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return models.Payment{}, 0, err
+	}
+
+	sendPaymentConfirmation(paymentRecord, profile, newBalance)
+
+	return paymentRecord, newBalance, nil
+}
+
+var errUTRUsed = errors.New("utr already recorded")
+
+// ensureUTRUnused rejects a bank transfer that is already posted. It is only an early, friendly check;
+// the unique index on payments.reference is what stops two concurrent submissions.
+func ensureUTRUnused(utr string) error {
+	var count int64
+	config.DB.Model(&models.Payment{}).Where("reference = ? AND status <> ?", utr, "reversed").Count(&count)
+	if count > 0 {
+		return errUTRUsed
+	}
+	return nil
+}
+
+// utrConflict reports a payment insert that lost the race on the unique reference index as a used UTR
+func utrConflict(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return errUTRUsed
+	}
+	return err
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UpdateLateFeeSettings sets the flat late fee and grace period for a property (amount 0 turns it off)
+func UpdateLateFeeSettings(propertyID, ownerID uint, amount float64, graceDays int) error {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return err
+	}
+	if amount < 0 {
+		return errors.New("late fee cannot be negative")
+	}
+	if graceDays < 0 || graceDays > 60 {
+		return errors.New("grace days must be between 0 and 60")
+	}
+
+	return config.DB.Model(&models.Property{}).Where("id = ?", propertyID).Updates(map[string]interface{}{
+		"late_fee_amount":     roundPaise(amount),
+		"late_fee_grace_days": graceDays,
+	}).Error
+}
+
+// ApplyLateFees raises one late fee invoice for each rent invoice still unpaid after the grace period.
+// Late fee invoices are never themselves penalised, and the late_fee_for link makes re-runs harmless.
+// A non-zero ownerID limits the run to that owner's properties.
+func ApplyLateFees(ownerID uint) error {
+	var properties []models.Property
+	if err := ownerScope(config.DB, "owner_id", ownerID).Where("late_fee_amount > 0").Find(&properties).Error; err != nil {
+		return err
+	}
+
+	applied, failed := 0, 0
+	for _, property := range properties {
+		cutoff := dateOnly(time.Now()).AddDate(0, 0, -property.LateFeeGraceDays)
+
+		var invoices []models.Invoice
+		config.DB.Where("property_id = ? AND status IN ? AND due_date < ? AND late_fee_for IS NULL", property.ID, []string{"open", "partial"}, cutoff).
+			Where("NOT EXISTS (SELECT 1 FROM invoices fee WHERE fee.late_fee_for = invoices.id)").
+			Find(&invoices)
+
+		for _, overdue := range invoices {
+			if err := applyLateFee(property, overdue); err != nil {
+				log.Printf("⚠️ Late fee for %s failed: %v", overdue.Reference, err)
+				failed++
+				continue
+			}
+			applied++
+		}
+	}
+
+	log.Printf("💸 Late fees: %d applied", applied)
+	if failed > 0 {
+		return fmt.Errorf("late fee failed for %d invoices", failed)
+	}
+	return nil
+}
+
+func applyLateFee(property models.Property, overdue models.Invoice) error {
+	var profile models.TenantProfile
+	var fee models.Invoice
+	var newBalance float64
+
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND status = ?", overdue.TenantID, "active").First(&profile).Error; err != nil {
+			return errors.New("tenant not active")
+		}
+
+		newBalance = profile.Balance + property.LateFeeAmount
+		if err := tx.Model(&profile).Update("balance", newBalance).Error; err != nil {
+			return err
+		}
+
+		var err error
+		fee, err = createInvoice(tx, profile, property.LateFeeAmount, "Late fee for "+overdue.Reference, time.Now())
+		if err != nil {
+			return err
+		}
+		return tx.Model(&fee).Update("late_fee_for", overdue.ID).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	invoiceURL, _ := invoicePaymentDetails(fee, profile.Name)
+	notifyTenant(profile, "account_adjustment", map[string]interface{}{
+		"Kind":       "late fee",
+		"Amount":     property.LateFeeAmount,
+		"Reason":     fmt.Sprintf("%s unpaid %d days after the due date", overdue.Reference, daysBetween(overdue.DueDate, time.Now())),
+		"Balance":    newBalance,
+		"ReceiptURL": invoiceURL,
+	})
+	return nil
+}
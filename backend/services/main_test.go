@@ -0,0 +1,90 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"pg-manager-backend/notifier"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TestMain runs the package with every outbound message captured in memory. Tests that need Postgres
+// run only when TEST_DB_NAME names a throwaway database (DB_HOST, DB_USER, ... as for the app); they
+// empty the tables they use.
+func TestMain(m *testing.M) {
+	os.Setenv("NOTIFIER_MODE", "capture")
+	config.LoadConfig()
+
+	if name := os.Getenv("TEST_DB_NAME"); name != "" {
+		config.App.DBName = name
+		config.ConnectDatabase()
+	} else {
+		log.Println("Info: TEST_DB_NAME not set, skipping tests that need Postgres")
+	}
+	os.Exit(m.Run())
+}
+
+// requireDB skips a test when no test database is configured and starts it with an empty outbox
+func requireDB(t *testing.T) {
+	t.Helper()
+	if config.DB == nil {
+		t.Skip("needs Postgres: set TEST_DB_NAME")
+	}
+	config.DB.Exec("DELETE FROM outbound_messages")
+	notifier.Captured().Reset()
+}
+
+// drainOutbox delivers every due message the way the outbox workers do and returns what was sent
+func drainOutbox(t *testing.T) []notifier.Message {
+	t.Helper()
+	for {
+		msg, err := claimNextMessage()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("claim: %v", err)
+		}
+		deliverMessage(msg)
+	}
+	return notifier.Captured().Outbox()
+}
+
+// createTestTenant adds an owner, a property with one room and an active tenant in it
+func createTestTenant(t *testing.T, tenant models.TenantProfile) (models.Property, models.TenantProfile) {
+	t.Helper()
+	suffix := time.Now().UnixNano() % 1e9
+
+	owner := models.User{Name: "Owner", Role: "owner", Phone: fmt.Sprintf("+9170%08d", suffix)}
+	if err := config.DB.Create(&owner).Error; err != nil {
+		t.Fatalf("create owner: %v", err)
+	}
+	property := models.Property{Name: "Sri Sai PG", OwnerID: owner.ID}
+	if err := config.DB.Create(&property).Error; err != nil {
+		t.Fatalf("create property: %v", err)
+	}
+
+	room := models.Room{PropertyID: property.ID, RoomNumber: "101", Capacity: 2, Price: 8000}
+	if err := config.DB.Create(&room).Error; err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+
+	user := models.User{Name: tenant.Name, Role: "tenant", Phone: fmt.Sprintf("+9198%08d", suffix)}
+	if err := config.DB.Create(&user).Error; err != nil {
+		t.Fatalf("create tenant user: %v", err)
+	}
+	tenant.UserID = user.ID
+	tenant.PropertyID = property.ID
+	tenant.RoomID = room.ID
+	tenant.PhoneNumber = user.Phone
+	if err := config.DB.Create(&tenant).Error; err != nil {
+		t.Fatalf("create tenant: %v", err)
+	}
+	return property, tenant
+}
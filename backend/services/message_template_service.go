@@ -0,0 +1,360 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Supported tenant languages; anything else falls back to English
+var templateLocales = []string{"en", "kn", "hi"}
+
+type templateText struct {
+	Subject string
+	Body    string
+}
+
+// defaultTemplates are the built-in messages used until a property saves its own version.
+// Keys available to each template are listed in templateSampleData.
+var defaultTemplates = map[string]map[string]templateText{
+	"rent_due": {
+		"en": {"Monthly Rent Due", "Namaste {{.Name}}! 🏠\nYour monthly rent of ₹{{money .Rent}} is due. Total Balance: ₹{{money .Balance}}.\nClick here to pay: {{.PayLink}}" +
+			"{{if .UPILink}}\nPay ₹{{money .AmountDue}} by UPI (Ref {{.Reference}}): {{.UPILink}}{{end}}" +
+			"{{if .InvoiceURL}}\nInvoice with UPI QR: {{.InvoiceURL}}{{end}}"},
+		"kn": {"ಮಾಸಿಕ ಬಾಡಿಗೆ ಬಾಕಿ", "ನಮಸ್ತೆ {{.Name}}! 🏠\nನಿಮ್ಮ ಮಾಸಿಕ ಬಾಡಿಗೆ ₹{{money .Rent}} ಪಾವತಿಸಬೇಕಾಗಿದೆ. ಒಟ್ಟು ಬಾಕಿ: ₹{{money .Balance}}.\nಪಾವತಿಸಲು ಇಲ್ಲಿ ಕ್ಲಿಕ್ ಮಾಡಿ: {{.PayLink}}" +
+			"{{if .UPILink}}\nUPI ಮೂಲಕ ₹{{money .AmountDue}} ಪಾವತಿಸಿ (ಉಲ್ಲೇಖ {{.Reference}}): {{.UPILink}}{{end}}" +
+			"{{if .InvoiceURL}}\nUPI QR ಇರುವ ಇನ್‌ವಾಯ್ಸ್: {{.InvoiceURL}}{{end}}"},
+		"hi": {"मासिक किराया देय", "नमस्ते {{.Name}}! 🏠\nआपका मासिक किराया ₹{{money .Rent}} देय है। कुल बकाया: ₹{{money .Balance}}।\nभुगतान के लिए यहाँ क्लिक करें: {{.PayLink}}" +
+			"{{if .UPILink}}\nUPI से ₹{{money .AmountDue}} भुगतान करें (संदर्भ {{.Reference}}): {{.UPILink}}{{end}}" +
+			"{{if .InvoiceURL}}\nUPI QR वाला इनवॉइस: {{.InvoiceURL}}{{end}}"},
+	},
+	"rent_reminder": {
+		"en": {"Rent Reminder", "⏰ Namaste {{.Name}}, your rent of ₹{{money .Rent}} is due {{if eq .DaysLeft 0}}today{{else}}in {{.DaysLeft}} day(s), on {{.DueDate}}{{end}}.\nCurrent Balance: ₹{{money .Balance}}" +
+			"{{if .PayLink}}\nPay here: {{.PayLink}}{{end}}"},
+		"kn": {"ಬಾಡಿಗೆ ಜ್ಞಾಪನೆ", "⏰ ನಮಸ್ತೆ {{.Name}}, ನಿಮ್ಮ ಬಾಡಿಗೆ ₹{{money .Rent}} {{if eq .DaysLeft 0}}ಇಂದು{{else}}{{.DaysLeft}} ದಿನಗಳಲ್ಲಿ ({{.DueDate}}){{end}} ಪಾವತಿಸಬೇಕಾಗಿದೆ.\nಪ್ರಸ್ತುತ ಬಾಕಿ: ₹{{money .Balance}}" +
+			"{{if .PayLink}}\nಇಲ್ಲಿ ಪಾವತಿಸಿ: {{.PayLink}}{{end}}"},
+		"hi": {"किराया अनुस्मारक", "⏰ नमस्ते {{.Name}}, आपका किराया ₹{{money .Rent}} {{if eq .DaysLeft 0}}आज{{else}}{{.DaysLeft}} दिन में ({{.DueDate}}){{end}} देय है।\nवर्तमान बकाया: ₹{{money .Balance}}" +
+			"{{if .PayLink}}\nयहाँ भुगतान करें: {{.PayLink}}{{end}}"},
+	},
+	"rent_overdue": {
+		"en": {"Rent Overdue", "⚠️ Namaste {{.Name}}, ₹{{money .AmountDue}} for invoice {{.Reference}} is {{.DaysOverdue}} day(s) overdue.\nPlease pay at the earliest: {{.PayLink}}" +
+			"{{if .UPILink}}\nPay by UPI: {{.UPILink}}{{end}}"},
+		"kn": {"ಬಾಡಿಗೆ ಬಾಕಿ ಮೀರಿದೆ", "⚠️ ನಮಸ್ತೆ {{.Name}}, ಇನ್‌ವಾಯ್ಸ್ {{.Reference}} ನ ₹{{money .AmountDue}} {{.DaysOverdue}} ದಿನಗಳಿಂದ ಬಾಕಿ ಇದೆ.\nದಯವಿಟ್ಟು ಶೀಘ್ರ ಪಾವತಿಸಿ: {{.PayLink}}" +
+			"{{if .UPILink}}\nUPI ಮೂಲಕ ಪಾವತಿಸಿ: {{.UPILink}}{{end}}"},
+		"hi": {"किराया बकाया", "⚠️ नमस्ते {{.Name}}, इनवॉइस {{.Reference}} के ₹{{money .AmountDue}} {{.DaysOverdue}} दिन से बकाया हैं।\nकृपया जल्द भुगतान करें: {{.PayLink}}" +
+			"{{if .UPILink}}\nUPI से भुगतान करें: {{.UPILink}}{{end}}"},
+	},
+	"payment_received": {
+		"en": {"Payment Received", "✅ Payment Received!\n\nNamaste {{.Name}}, we received ₹{{money .Amount}} via {{.Method}}.\nNew Balance: ₹{{money .Balance}}" +
+			"{{if .ReceiptURL}}\nDownload Receipt: {{.ReceiptURL}}{{end}}"},
+		"kn": {"ಪಾವತಿ ಸ್ವೀಕರಿಸಲಾಗಿದೆ", "✅ ಪಾವತಿ ಸ್ವೀಕರಿಸಲಾಗಿದೆ!\n\nನಮಸ್ತೆ {{.Name}}, {{.Method}} ಮೂಲಕ ₹{{money .Amount}} ಸ್ವೀಕರಿಸಿದ್ದೇವೆ.\nಹೊಸ ಬಾಕಿ: ₹{{money .Balance}}" +
+			"{{if .ReceiptURL}}\nರಸೀದಿ ಡೌನ್‌ಲೋಡ್ ಮಾಡಿ: {{.ReceiptURL}}{{end}}"},
+		"hi": {"भुगतान प्राप्त हुआ", "✅ भुगतान प्राप्त हुआ!\n\nनमस्ते {{.Name}}, हमें {{.Method}} द्वारा ₹{{money .Amount}} प्राप्त हुए।\nनया बकाया: ₹{{money .Balance}}" +
+			"{{if .ReceiptURL}}\nरसीद डाउनलोड करें: {{.ReceiptURL}}{{end}}"},
+	},
+	"admission_confirmed": {
+		"en": {"Admission Confirmed", "✅ Admission Confirmed!\nYour initial total (Rent+Deposit) is ₹{{money .AmountDue}}.\nPay here: {{.PayLink}}" +
+			"{{if or .UPILink .InvoiceURL}}\nPay by UPI (Ref {{.Reference}}): {{.UPILink}}\nInvoice: {{.InvoiceURL}}{{end}}"},
+		"kn": {"ಪ್ರವೇಶ ದೃಢೀಕರಿಸಲಾಗಿದೆ", "✅ ಪ್ರವೇಶ ದೃಢೀಕರಿಸಲಾಗಿದೆ!\nನಿಮ್ಮ ಆರಂಭಿಕ ಮೊತ್ತ (ಬಾಡಿಗೆ+ಠೇವಣಿ) ₹{{money .AmountDue}}.\nಇಲ್ಲಿ ಪಾವತಿಸಿ: {{.PayLink}}" +
+			"{{if or .UPILink .InvoiceURL}}\nUPI ಮೂಲಕ ಪಾವತಿಸಿ (ಉಲ್ಲೇಖ {{.Reference}}): {{.UPILink}}\nಇನ್‌ವಾಯ್ಸ್: {{.InvoiceURL}}{{end}}"},
+		"hi": {"प्रवेश की पुष्टि", "✅ प्रवेश की पुष्टि हो गई!\nआपकी प्रारंभिक राशि (किराया+जमा) ₹{{money .AmountDue}} है।\nयहाँ भुगतान करें: {{.PayLink}}" +
+			"{{if or .UPILink .InvoiceURL}}\nUPI से भुगतान करें (संदर्भ {{.Reference}}): {{.UPILink}}\nइनवॉइस: {{.InvoiceURL}}{{end}}"},
+	},
+	"complaint_resolved": {
+		"en": {"Complaint Resolved", "🛠️ Namaste {{.Name}}, your {{.Category}} complaint #{{.ComplaintID}} has been resolved." +
+			"{{if .RatingLink}}\nHow did we do? Rate here: {{.RatingLink}}{{end}}" +
+			"{{if .ReopenDays}}\nStill not fixed? Reopen it from the same link within {{.ReopenDays}} days.{{end}}"},
+		"kn": {"ದೂರು ಪರಿಹರಿಸಲಾಗಿದೆ", "🛠️ ನಮಸ್ತೆ {{.Name}}, ನಿಮ್ಮ {{.Category}} ದೂರು #{{.ComplaintID}} ಪರಿಹರಿಸಲಾಗಿದೆ." +
+			"{{if .RatingLink}}\nನಮ್ಮ ಸೇವೆಯನ್ನು ಇಲ್ಲಿ ರೇಟ್ ಮಾಡಿ: {{.RatingLink}}{{end}}" +
+			"{{if .ReopenDays}}\nಸಮಸ್ಯೆ ಇನ್ನೂ ಇದೆಯೇ? {{.ReopenDays}} ದಿನಗಳೊಳಗೆ ಅದೇ ಲಿಂಕ್‌ನಿಂದ ಮರುತೆರೆಯಿರಿ.{{end}}"},
+		"hi": {"शिकायत का समाधान", "🛠️ नमस्ते {{.Name}}, आपकी {{.Category}} शिकायत #{{.ComplaintID}} का समाधान हो गया है।" +
+			"{{if .RatingLink}}\nहमारी सेवा को यहाँ रेट करें: {{.RatingLink}}{{end}}" +
+			"{{if .ReopenDays}}\nसमस्या अभी भी है? {{.ReopenDays}} दिनों के भीतर इसी लिंक से दोबारा खोलें।{{end}}"},
+	},
+	"announcement": {
+		"en": {"{{.Title}}", "📢 {{.Title}}\n\n{{.Body}}\n\n— {{.PropertyName}}"},
+		"kn": {"{{.Title}}", "📢 {{.Title}}\n\n{{.Body}}\n\n— {{.PropertyName}}"},
+		"hi": {"{{.Title}}", "📢 {{.Title}}\n\n{{.Body}}\n\n— {{.PropertyName}}"},
+	},
+	"otp": {
+		"en": {"Admission OTP", "Namaste {{.Name}}! Your PG admission OTP is {{.OTP}}. Share it with the owner to confirm your admission."},
+		"kn": {"ಪ್ರವೇಶ OTP", "ನಮಸ್ತೆ {{.Name}}! ನಿಮ್ಮ PG ಪ್ರವೇಶ OTP {{.OTP}}. ಪ್ರವೇಶ ದೃಢೀಕರಿಸಲು ಇದನ್ನು ಮಾಲೀಕರೊಂದಿಗೆ ಹಂಚಿಕೊಳ್ಳಿ."},
+		"hi": {"प्रवेश OTP", "नमस्ते {{.Name}}! आपका PG प्रवेश OTP {{.OTP}} है। प्रवेश की पुष्टि के लिए इसे मालिक के साथ साझा करें।"},
+	},
+	"account_adjustment": {
+		"en": {"Account Adjustment", "ℹ️ Account Adjustment\n\nNamaste {{.Name}}, a {{.Kind}} of ₹{{money .Amount}} was recorded.\nReason: {{.Reason}}\nNew Balance: ₹{{money .Balance}}\nDetails: {{.ReceiptURL}}"},
+		"kn": {"ಖಾತೆ ಹೊಂದಾಣಿಕೆ", "ℹ️ ಖಾತೆ ಹೊಂದಾಣಿಕೆ\n\nನಮಸ್ತೆ {{.Name}}, ₹{{money .Amount}} ರ {{.Kind}} ದಾಖಲಿಸಲಾಗಿದೆ.\nಕಾರಣ: {{.Reason}}\nಹೊಸ ಬಾಕಿ: ₹{{money .Balance}}\nವಿವರಗಳು: {{.ReceiptURL}}"},
+		"hi": {"खाता समायोजन", "ℹ️ खाता समायोजन\n\nनमस्ते {{.Name}}, ₹{{money .Amount}} का {{.Kind}} दर्ज किया गया।\nकारण: {{.Reason}}\nनया बकाया: ₹{{money .Balance}}\nविवरण: {{.ReceiptURL}}"},
+	},
+}
+
+// templateSampleData is used to validate edits and render previews
+var templateSampleData = map[string]map[string]interface{}{
+	"rent_due": {"Name": "Ravi", "Rent": 8000.0, "Balance": 8000.0, "AmountDue": 8000.0, "PayLink": "https://rzp.io/l/sample",
+		"UPILink": "upi://pay?pa=pg@upi&am=8000.00&tr=INV-000001", "Reference": "INV-000001", "InvoiceURL": "https://example.com/invoices/invoice_1.pdf"},
+	"rent_reminder": {"Name": "Ravi", "Rent": 8000.0, "Balance": 0.0, "DaysLeft": 3, "DueDate": "05 Nov 2024", "PayLink": "https://rzp.io/l/sample"},
+	"rent_overdue": {"Name": "Ravi", "AmountDue": 8000.0, "Reference": "INV-000001", "DaysOverdue": 3, "PayLink": "https://rzp.io/l/sample",
+		"UPILink": "upi://pay?pa=pg@upi&am=8000.00&tr=INV-000001"},
+	"payment_received":    {"Name": "Ravi", "Amount": 8000.0, "Method": "UPI", "Balance": 0.0, "ReceiptURL": "https://example.com/receipts/receipt_1.pdf"},
+	"admission_confirmed": {"Name": "Ravi", "AmountDue": 18000.0, "PayLink": "https://rzp.io/l/sample", "UPILink": "upi://pay?pa=pg@upi&am=18000.00&tr=INV-000001", "Reference": "INV-000001", "InvoiceURL": "https://example.com/invoices/invoice_1.pdf"},
+	"complaint_resolved":  {"Name": "Ravi", "Category": "Plumbing", "ComplaintID": 42, "RatingLink": "https://example.com/rate/sample", "ReopenDays": 7},
+	"announcement":        {"Name": "Ravi", "Title": "Water supply interruption", "Body": "No water from 10 AM to 2 PM on Sunday due to tank cleaning.", "PropertyName": "Sri Sai PG"},
+	"otp":                 {"Name": "Ravi", "OTP": "123456"},
+	"account_adjustment":  {"Name": "Ravi", "Kind": "refund", "Amount": 500.0, "Reason": "Overpayment", "Balance": 0.0, "ReceiptURL": "https://example.com/receipts/receipt_2.pdf"},
+}
+
+var templateFuncs = template.FuncMap{
+	"money": func(v interface{}) string {
+		switch n := v.(type) {
+		case float64:
+			return fmt.Sprintf("%.2f", n)
+		case int:
+			return fmt.Sprintf("%d.00", n)
+		}
+		return fmt.Sprint(v)
+	},
+}
+
+// RenderedMessage is a template filled in for one recipient
+type RenderedMessage struct {
+	Subject      string            `json:"subject"`
+	Body         string            `json:"body"`
+	TemplateSID  string            `json:"template_sid,omitempty"`
+	TemplateVars map[string]string `json:"template_vars,omitempty"`
+}
+
+// EffectiveTemplate is what a property will actually send for a name/locale
+type EffectiveTemplate struct {
+	Name               string `json:"name"`
+	Locale             string `json:"locale"`
+	Version            int    `json:"version"` // 0 = built-in default
+	Subject            string `json:"subject"`
+	Body               string `json:"body"`
+	WhatsAppContentSID string `json:"whatsapp_content_sid"`
+	WhatsAppVariables  string `json:"whatsapp_variables"`
+	IsDefault          bool   `json:"is_default"`
+}
+
+func normalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	for _, l := range templateLocales {
+		if l == locale {
+			return l
+		}
+	}
+	return "en"
+}
+
+// effectiveTemplate returns the active override for the property, or the built-in default
+func effectiveTemplate(propertyID uint, name, locale string) (EffectiveTemplate, error) {
+	defaults, ok := defaultTemplates[name]
+	if !ok {
+		return EffectiveTemplate{}, errors.New("unknown template")
+	}
+
+	var override models.MessageTemplate
+	err := config.DB.Where("property_id = ? AND name = ? AND locale = ? AND is_active = ?", propertyID, name, locale, true).
+		Order("version DESC").First(&override).Error
+	if err == nil {
+		return EffectiveTemplate{
+			Name: name, Locale: locale, Version: override.Version,
+			Subject: override.Subject, Body: override.Body,
+			WhatsAppContentSID: override.WhatsAppContentSID, WhatsAppVariables: override.WhatsAppVariables,
+		}, nil
+	}
+
+	text := defaults[locale]
+	return EffectiveTemplate{Name: name, Locale: locale, Subject: text.Subject, Body: text.Body, IsDefault: true}, nil
+}
+
+func executeTemplate(name, text string, data map[string]interface{}) (string, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// renderEffective fills in a template; WhatsApp variables map the listed data keys to {{1}}, {{2}}, ...
+func renderEffective(tpl EffectiveTemplate, data map[string]interface{}) (RenderedMessage, error) {
+	subject, err := executeTemplate(tpl.Name+"_subject", tpl.Subject, data)
+	if err != nil {
+		return RenderedMessage{}, err
+	}
+	body, err := executeTemplate(tpl.Name, tpl.Body, data)
+	if err != nil {
+		return RenderedMessage{}, err
+	}
+
+	rendered := RenderedMessage{Subject: subject, Body: body}
+	if tpl.WhatsAppContentSID != "" {
+		rendered.TemplateSID = tpl.WhatsAppContentSID
+		rendered.TemplateVars = map[string]string{}
+		for i, key := range strings.Split(tpl.WhatsAppVariables, ",") {
+			key = strings.TrimSpace(key)
+			if key == "" {
+				continue
+			}
+			value := data[key]
+			if f, ok := value.(float64); ok {
+				rendered.TemplateVars[fmt.Sprint(i+1)] = fmt.Sprintf("%.2f", f)
+			} else if value != nil {
+				rendered.TemplateVars[fmt.Sprint(i+1)] = fmt.Sprint(value)
+			} else {
+				rendered.TemplateVars[fmt.Sprint(i+1)] = ""
+			}
+		}
+	}
+	return rendered, nil
+}
+
+// renderMessage renders a named template for a property in the tenant's language.
+// A broken override falls back to the built-in default so the tenant still gets notified.
+func renderMessage(propertyID uint, name, locale string, data map[string]interface{}) (RenderedMessage, error) {
+	locale = normalizeLocale(locale)
+	tpl, err := effectiveTemplate(propertyID, name, locale)
+	if err != nil {
+		return RenderedMessage{}, err
+	}
+
+	rendered, err := renderEffective(tpl, data)
+	if err != nil && !tpl.IsDefault {
+		text := defaultTemplates[name][locale]
+		return renderEffective(EffectiveTemplate{Name: name, Locale: locale, Subject: text.Subject, Body: text.Body, IsDefault: true}, data)
+	}
+	return rendered, err
+}
+
+// GetMessageTemplates lists the effective template for every name and locale of a property
+func GetMessageTemplates(propertyID, ownerID uint) ([]EffectiveTemplate, error) {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(defaultTemplates))
+	for name := range defaultTemplates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var result []EffectiveTemplate
+	for _, name := range names {
+		for _, locale := range templateLocales {
+			tpl, _ := effectiveTemplate(propertyID, name, locale)
+			result = append(result, tpl)
+		}
+	}
+	return result, nil
+}
+
+// SaveMessageTemplate stores an edit as a new version after checking it renders against sample data
+func SaveMessageTemplate(propertyID, ownerID uint, input models.MessageTemplate) (models.MessageTemplate, error) {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return models.MessageTemplate{}, err
+	}
+	if _, ok := defaultTemplates[input.Name]; !ok {
+		return models.MessageTemplate{}, errors.New("unknown template")
+	}
+	if normalizeLocale(input.Locale) != input.Locale {
+		return models.MessageTemplate{}, errors.New("unsupported locale: use en, kn or hi")
+	}
+	if strings.TrimSpace(input.Body) == "" {
+		return models.MessageTemplate{}, errors.New("template body is required")
+	}
+
+	// 1. Validate: the template must parse and render with the sample data
+	candidate := EffectiveTemplate{Name: input.Name, Locale: input.Locale, Subject: input.Subject, Body: input.Body,
+		WhatsAppContentSID: input.WhatsAppContentSID, WhatsAppVariables: input.WhatsAppVariables}
+	if _, err := renderEffective(candidate, templateSampleData[input.Name]); err != nil {
+		return models.MessageTemplate{}, fmt.Errorf("invalid template: %v", err)
+	}
+
+	// 2. Next version number
+	var latest int
+	config.DB.Model(&models.MessageTemplate{}).
+		Where("property_id = ? AND name = ? AND locale = ?", propertyID, input.Name, input.Locale).
+		Select("COALESCE(MAX(version), 0)").Scan(&latest)
+
+	record := models.MessageTemplate{
+		PropertyID:         propertyID,
+		Name:               input.Name,
+		Locale:             input.Locale,
+		Version:            latest + 1,
+		Subject:            input.Subject,
+		Body:               input.Body,
+		WhatsAppContentSID: strings.TrimSpace(input.WhatsAppContentSID),
+		WhatsAppVariables:  strings.TrimSpace(input.WhatsAppVariables),
+		IsActive:           true,
+		CreatedBy:          ownerID,
+	}
+	if err := config.DB.Create(&record).Error; err != nil {
+		return models.MessageTemplate{}, errors.New("could not save template")
+	}
+	return record, nil
+}
+
+// GetMessageTemplateVersions lists every saved version, newest first
+func GetMessageTemplateVersions(propertyID, ownerID uint, name, locale string) ([]models.MessageTemplate, error) {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return nil, err
+	}
+	var versions []models.MessageTemplate
+	err := config.DB.Where("property_id = ? AND name = ? AND locale = ?", propertyID, name, locale).
+		Order("version DESC").Find(&versions).Error
+	return versions, err
+}
+
+// RestoreMessageTemplateVersion makes an old version current by copying it as a new version
+func RestoreMessageTemplateVersion(propertyID, ownerID uint, name, locale string, version int) (models.MessageTemplate, error) {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return models.MessageTemplate{}, err
+	}
+	var old models.MessageTemplate
+	if err := config.DB.Where("property_id = ? AND name = ? AND locale = ? AND version = ?", propertyID, name, locale, version).
+		First(&old).Error; err != nil {
+		return models.MessageTemplate{}, errors.New("template version not found")
+	}
+	return SaveMessageTemplate(propertyID, ownerID, old)
+}
+
+// ResetMessageTemplate deactivates all saved versions so the built-in default is used again
+func ResetMessageTemplate(propertyID, ownerID uint, name, locale string) error {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return err
+	}
+	return config.DB.Model(&models.MessageTemplate{}).
+		Where("property_id = ? AND name = ? AND locale = ?", propertyID, name, locale).
+		Update("is_active", false).Error
+}
+
+// PreviewMessageTemplate renders a draft (or the current template when body is empty) with sample data
+func PreviewMessageTemplate(propertyID, ownerID uint, draft models.MessageTemplate) (RenderedMessage, error) {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return RenderedMessage{}, err
+	}
+	if _, ok := defaultTemplates[draft.Name]; !ok {
+		return RenderedMessage{}, errors.New("unknown template")
+	}
+	locale := normalizeLocale(draft.Locale)
+
+	tpl, _ := effectiveTemplate(propertyID, draft.Name, locale)
+	if draft.Body != "" {
+		tpl = EffectiveTemplate{Name: draft.Name, Locale: locale, Subject: draft.Subject, Body: draft.Body,
+			WhatsAppContentSID: draft.WhatsAppContentSID, WhatsAppVariables: draft.WhatsAppVariables}
+	}
+
+	rendered, err := renderEffective(tpl, templateSampleData[draft.Name])
+	if err != nil {
+		return RenderedMessage{}, fmt.Errorf("invalid template: %v", err)
+	}
+	return rendered, nil
+}
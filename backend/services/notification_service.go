@@ -0,0 +1,255 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"pg-manager-backend/notifier"
+	"pg-manager-backend/utils"
+	"strings"
+	"time"
+)
+
+// Keywords tenants can send on WhatsApp/SMS to stop or resume messages on that channel
+var (
+	optOutKeywords = map[string]bool{"STOP": true, "STOPALL": true, "UNSUBSCRIBE": true, "OPTOUT": true}
+	optInKeywords  = map[string]bool{"START": true, "UNSTOP": true, "SUBSCRIBE": true}
+)
+
+// channelTarget is one way of reaching a tenant
+type channelTarget struct {
+	Channel string
+	To      string
+}
+
+// tenantChannels lists where a tenant can be reached, preferred channel first, then WhatsApp, SMS, email.
+// Channels the tenant opted out of, and email without a MailID, are left out.
+func tenantChannels(profile models.TenantProfile) []channelTarget {
+	available := map[string]channelTarget{}
+	if profile.WhatsAppOptOutAt == nil && profile.PhoneNumber != "" {
+		available[notifier.WhatsApp] = channelTarget{notifier.WhatsApp, profile.PhoneNumber}
+	}
+	if profile.SMSOptOutAt == nil && profile.PhoneNumber != "" {
+		available[notifier.SMS] = channelTarget{notifier.SMS, profile.PhoneNumber}
+	}
+	if profile.MailID != "" {
+		available[notifier.Email] = channelTarget{notifier.Email, profile.MailID}
+	}
+
+	var chain []channelTarget
+	for _, channel := range []string{profile.NotifyChannel, notifier.WhatsApp, notifier.SMS, notifier.Email} {
+		if target, ok := available[channel]; ok {
+			chain = append(chain, target)
+			delete(available, channel)
+		}
+	}
+	return chain
+}
+
+// notifyTenant renders the named template in the tenant's language and queues it on their preferred
+// channel; the outbox workers deliver it and fall back to the next channel if it fails.
+func notifyTenant(profile models.TenantProfile, templateName string, data map[string]interface{}) error {
+	_, err := queueTenantMessage(profile, templateName, data, time.Now())
+	return err
+}
+
+// queueTenantMessage is notifyTenant with a send time, returning the queued message
+func queueTenantMessage(profile models.TenantProfile, templateName string, data map[string]interface{}, sendAt time.Time) (models.OutboundMessage, error) {
+	if _, ok := data["Name"]; !ok {
+		data["Name"] = profile.Name
+	}
+
+	chain := tenantChannels(profile)
+	if len(chain) == 0 {
+		log.Printf("🔕 Not sending %s to %s: opted out of every channel", templateName, profile.Name)
+		return models.OutboundMessage{}, errors.New("tenant has opted out of all channels")
+	}
+
+	rendered, err := renderMessage(profile.PropertyID, templateName, profile.Language, data)
+	if err != nil {
+		log.Printf("⚠️ Could not render %s for %s: %v", templateName, profile.Name, err)
+		return models.OutboundMessage{}, err
+	}
+
+	msg := notifier.Message{
+		Channel: chain[0].Channel,
+		To:      chain[0].To,
+		Subject: rendered.Subject,
+		Body:    rendered.Body,
+	}
+	if msg.Channel == notifier.WhatsApp {
+		msg.TemplateSID = rendered.TemplateSID
+		msg.TemplateVars = rendered.TemplateVars
+	}
+
+	record, err := enqueueMessageAt(msg, profile.PropertyID, profile.UserID, templateName, sendAt)
+	if err != nil {
+		log.Printf("⚠️ Could not queue %s for %s: %v", templateName, profile.Name, err)
+	}
+	return record, err
+}
+
+// fallbackToNextChannel re-queues a failed tenant message on the next channel in the tenant's chain.
+// Each message falls back at most once; the fallback can fall back again in turn.
+func fallbackToNextChannel(failed models.OutboundMessage) {
+	if failed.TenantID == 0 || failed.Kind == "chatbot_reply" {
+		return
+	}
+
+	var existing int64
+	config.DB.Model(&models.OutboundMessage{}).Where("fallback_of = ?", failed.ID).Count(&existing)
+	if existing > 0 {
+		return
+	}
+
+	var profile models.TenantProfile
+	if err := config.DB.Where("user_id = ?", failed.TenantID).First(&profile).Error; err != nil {
+		return
+	}
+
+	// Skip every channel already tried for this notification
+	tried := map[string]bool{failed.Channel: true}
+	for parent := failed.FallbackOf; parent != nil; {
+		var earlier models.OutboundMessage
+		if err := config.DB.Select("id, channel, fallback_of").First(&earlier, *parent).Error; err != nil {
+			break
+		}
+		tried[earlier.Channel] = true
+		parent = earlier.FallbackOf
+	}
+
+	for _, target := range tenantChannels(profile) {
+		if tried[target.Channel] {
+			continue
+		}
+
+		record, err := enqueueMessage(notifier.Message{
+			Channel: target.Channel,
+			To:      target.To,
+			Subject: failed.Subject,
+			Body:    failed.Body,
+		}, failed.PropertyID, failed.TenantID, failed.Kind)
+		if err != nil {
+			log.Printf("⚠️ Could not queue fallback for message %d: %v", failed.ID, err)
+			return
+		}
+		config.DB.Model(&record).Update("fallback_of", failed.ID)
+		log.Printf("↪️ Message %d failed on %s; retrying on %s as %d", failed.ID, failed.Channel, target.Channel, record.ID)
+		return
+	}
+}
+
+// handleConsentKeyword records STOP/START replies. It returns true when the message was a keyword,
+// so the chatbot does not also treat it as a command.
+func handleConsentKeyword(inbound models.InboundMessage, profile models.TenantProfile) bool {
+	keyword := strings.ToUpper(strings.TrimSpace(inbound.Body))
+	optOut, optIn := optOutKeywords[keyword], optInKeywords[keyword]
+	if !optOut && !optIn || profile.UserID == 0 {
+		return false
+	}
+
+	column := "whatsapp_opt_out_at"
+	if inbound.Channel == notifier.SMS {
+		column = "sms_opt_out_at"
+	}
+
+	action := "opt_in"
+	var value interface{}
+	if optOut {
+		action = "opt_out"
+		value = time.Now()
+	}
+
+	config.DB.Model(&models.TenantProfile{}).Where("user_id = ?", profile.UserID).Update(column, value)
+	config.DB.Create(&models.ConsentEvent{
+		PropertyID:  profile.PropertyID,
+		TenantID:    profile.UserID,
+		PhoneNumber: inbound.From,
+		Channel:     inbound.Channel,
+		Action:      action,
+		Keyword:     keyword,
+		InboundID:   inbound.ID,
+	})
+	log.Printf("🔕 %s %s on %s (%s)", profile.Name, action, inbound.Channel, keyword)
+
+	// Carriers confirm SMS STOP/START themselves; on WhatsApp we confirm
+	if inbound.Channel == notifier.WhatsApp {
+		reply := "You will no longer receive messages from your PG on WhatsApp. Reply START to resume."
+		if optIn {
+			reply = "You're subscribed again. Send HELP to see what I can do."
+		}
+		replyToSender(inbound, profile.PropertyID, profile.UserID, reply)
+	}
+	return true
+}
+
+// validateContactDetails normalizes the phone to E.164 and checks email and channel choices
+func validateContactDetails(profile *models.TenantProfile) error {
+	phone, err := utils.NormalizePhone(profile.PhoneNumber)
+	if err != nil {
+		return err
+	}
+	profile.PhoneNumber = phone
+
+	profile.MailID = strings.TrimSpace(profile.MailID)
+	if profile.MailID != "" && !utils.IsValidEmail(profile.MailID) {
+		return errors.New("invalid email address")
+	}
+
+	switch profile.NotifyChannel {
+	case "":
+		profile.NotifyChannel = notifier.WhatsApp
+	case notifier.WhatsApp, notifier.SMS:
+	case notifier.Email:
+		if profile.MailID == "" {
+			return errors.New("invalid notify channel: email needs a mail_id")
+		}
+	default:
+		return errors.New("invalid notify channel: use whatsapp, sms or email")
+	}
+
+	profile.Language = normalizeLocale(profile.Language)
+	return nil
+}
+
+// UpdateTenantNotificationPrefs lets the owner change a tenant's channel, language and email.
+// Opt-outs are not editable here: only the tenant can opt back in by replying START.
+func UpdateTenantNotificationPrefs(tenantID, ownerID uint, channel, language, mailID string) (models.TenantProfile, error) {
+	var profile models.TenantProfile
+	if err := config.DB.Where("user_id = ?", tenantID).First(&profile).Error; err != nil {
+		return profile, errors.New("tenant not found")
+	}
+	if err := verifyPropertyOwner(profile.PropertyID, ownerID); err != nil {
+		return profile, err
+	}
+
+	profile.NotifyChannel = channel
+	profile.Language = language
+	profile.MailID = mailID
+	if err := validateContactDetails(&profile); err != nil {
+		return profile, err
+	}
+
+	err := config.DB.Model(&profile).Updates(map[string]interface{}{
+		"notify_channel": profile.NotifyChannel,
+		"language":       profile.Language,
+		"mail_id":        profile.MailID,
+	}).Error
+	return profile, err
+}
+
+// GetTenantConsentHistory returns the tenant's opt-out/opt-in events for compliance checks
+func GetTenantConsentHistory(tenantID, ownerID uint) ([]models.ConsentEvent, error) {
+	var profile models.TenantProfile
+	if err := config.DB.Where("user_id = ?", tenantID).First(&profile).Error; err != nil {
+		return nil, errors.New("tenant not found")
+	}
+	if err := verifyPropertyOwner(profile.PropertyID, ownerID); err != nil {
+		return nil, err
+	}
+
+	var events []models.ConsentEvent
+	err := config.DB.Where("tenant_id = ?", tenantID).Order("created_at DESC").Find(&events).Error
+	return events, err
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"pg-manager-backend/notifier"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefaultTemplatesRender(t *testing.T) {
+	for name, locales := range defaultTemplates {
+		for _, locale := range templateLocales {
+			text, ok := locales[locale]
+			if !ok {
+				t.Errorf("%s has no %s text", name, locale)
+				continue
+			}
+			rendered, err := renderEffective(EffectiveTemplate{Name: name, Locale: locale, Subject: text.Subject, Body: text.Body, IsDefault: true}, templateSampleData[name])
+			if err != nil {
+				t.Errorf("%s/%s: %v", name, locale, err)
+				continue
+			}
+			if strings.Contains(rendered.Body, "<no value>") || strings.Contains(rendered.Subject, "<no value>") {
+				t.Errorf("%s/%s uses a key missing from templateSampleData:\n%s", name, locale, rendered.Body)
+			}
+		}
+	}
+}
+
+func TestTenantChannels(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name    string
+		profile models.TenantProfile
+		want    []string
+	}{
+		{"whatsapp first by default", models.TenantProfile{NotifyChannel: "whatsapp", PhoneNumber: "+919845012345", MailID: "a@b.in"}, []string{"whatsapp", "sms", "email"}},
+		{"preferred channel first", models.TenantProfile{NotifyChannel: "email", PhoneNumber: "+919845012345", MailID: "a@b.in"}, []string{"email", "whatsapp", "sms"}},
+		{"opted out of whatsapp", models.TenantProfile{NotifyChannel: "whatsapp", PhoneNumber: "+919845012345", WhatsAppOptOutAt: &now}, []string{"sms"}},
+		{"no email address", models.TenantProfile{NotifyChannel: "email", PhoneNumber: "+919845012345"}, []string{"whatsapp", "sms"}},
+		{"opted out everywhere", models.TenantProfile{PhoneNumber: "+919845012345", WhatsAppOptOutAt: &now, SMSOptOutAt: &now}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, target := range tenantChannels(tt.profile) {
+				got = append(got, target.Channel)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaymentConfirmationIsSent(t *testing.T) {
+	requireDB(t)
+	_, tenant := createTestTenant(t, models.TenantProfile{Name: "Ravi"})
+
+	if err := notifyTenant(tenant, "payment_received", map[string]interface{}{"Amount": 1500.0, "Method": "UPI", "Balance": 500.0}); err != nil {
+		t.Fatalf("notifyTenant: %v", err)
+	}
+
+	sent := drainOutbox(t)
+	if len(sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(sent))
+	}
+	msg := sent[0]
+	if msg.Channel != notifier.WhatsApp || msg.To != tenant.PhoneNumber {
+		t.Errorf("sent on %s to %s, want whatsapp to %s", msg.Channel, msg.To, tenant.PhoneNumber)
+	}
+	for _, want := range []string{"Namaste Ravi", "we received ₹1500.00 via UPI", "New Balance: ₹500.00"} {
+		if !strings.Contains(msg.Body, want) {
+			t.Errorf("body missing %q:\n%s", want, msg.Body)
+		}
+	}
+	if strings.Contains(msg.Body, "Download Receipt") {
+		t.Errorf("receipt line rendered without a receipt URL:\n%s", msg.Body)
+	}
+}
+
+func TestMessageFollowsLanguageAndOptOut(t *testing.T) {
+	requireDB(t)
+	now := time.Now()
+	_, tenant := createTestTenant(t, models.TenantProfile{Name: "Kavya", Language: "kn"})
+	config.DB.Model(&tenant).Update("whatsapp_opt_out_at", &now)
+	tenant.WhatsAppOptOutAt = &now
+
+	if err := notifyTenant(tenant, "payment_received", map[string]interface{}{"Amount": 8000.0, "Method": "Cash", "Balance": 0.0}); err != nil {
+		t.Fatalf("notifyTenant: %v", err)
+	}
+
+	sent := drainOutbox(t)
+	if len(sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(sent))
+	}
+	if sent[0].Channel != notifier.SMS {
+		t.Errorf("sent on %s, want sms after the WhatsApp opt-out", sent[0].Channel)
+	}
+	if !strings.Contains(sent[0].Body, "ನಮಸ್ತೆ Kavya") || !strings.Contains(sent[0].Body, "₹8000.00") {
+		t.Errorf("want the Kannada text, got:\n%s", sent[0].Body)
+	}
+}
+
+func TestPropertyTemplateOverridesDefault(t *testing.T) {
+	requireDB(t)
+	property, tenant := createTestTenant(t, models.TenantProfile{Name: "Ravi"})
+	config.DB.Create(&models.MessageTemplate{
+		PropertyID: property.ID, Name: "payment_received", Locale: "en", Version: 1,
+		Subject: "Thanks", Body: "Hi {{.Name}}, got ₹{{money .Amount}}. Balance ₹{{money .Balance}}.", IsActive: true,
+	})
+
+	notifyTenant(tenant, "payment_received", map[string]interface{}{"Amount": 2000.0, "Method": "UPI", "Balance": 0.0})
+
+	sent := drainOutbox(t)
+	if len(sent) != 1 || sent[0].Body != "Hi Ravi, got ₹2000.00. Balance ₹0.00." {
+		t.Fatalf("want the property's own template, got %+v", sent)
+	}
+}
+
+func TestComplaintResolvedCarriesFeedbackLink(t *testing.T) {
+	requireDB(t)
+	property, tenant := createTestTenant(t, models.TenantProfile{Name: "Ravi"})
+	complaint := models.Complaint{PropertyID: property.ID, TenantID: tenant.UserID, TenantName: tenant.Name,
+		PhoneNumber: tenant.PhoneNumber, Category: "Plumbing", Description: "Tap leaking", Status: "resolved"}
+	config.DB.Create(&complaint)
+
+	notifyComplaintResolved(complaint)
+
+	sent := drainOutbox(t)
+	if len(sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(sent))
+	}
+	if !strings.Contains(sent[0].Body, complaintFeedbackLink(complaint.ID)) {
+		t.Errorf("body missing the signed feedback link:\n%s", sent[0].Body)
+	}
+}
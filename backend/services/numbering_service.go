@@ -0,0 +1,117 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var seriesPrefixPattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9/-]{0,15}$`)
+
+// financialYear is the Indian April–March year a date falls in, as "24-25"
+func financialYear(date time.Time) string {
+	start := date.Year()
+	if date.Month() < time.April {
+		start--
+	}
+	return fmt.Sprintf("%02d-%02d", start%100, (start+1)%100)
+}
+
+// propertyCode is the default prefix: the first three letters of the property name ("Sri Sai PG" → "SRI")
+func propertyCode(property models.Property) string {
+	var code []rune
+	for _, r := range strings.ToUpper(property.Name) {
+		if r <= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			code = append(code, r)
+			if len(code) == 3 {
+				break
+			}
+		}
+	}
+	if len(code) == 0 {
+		return fmt.Sprintf("P%d", property.ID)
+	}
+	return string(code)
+}
+
+// seriesPrefix returns the configured or default prefix for a kind of document
+func seriesPrefix(property models.Property, kind string) string {
+	if kind == "invoice" {
+		if property.InvoicePrefix != "" {
+			return property.InvoicePrefix
+		}
+		return propertyCode(property) + "/INV"
+	}
+	if property.ReceiptPrefix != "" {
+		return property.ReceiptPrefix
+	}
+	return propertyCode(property)
+}
+
+// nextDocumentNumber issues the next number in the property's series for the date's financial year.
+// It must run in the same transaction that stores the document: the series row stays locked until
+// commit, and a rollback hands the number back, so the series never has gaps.
+func nextDocumentNumber(tx *gorm.DB, propertyID uint, kind string, date time.Time) (string, error) {
+	var property models.Property
+	if err := tx.First(&property, propertyID).Error; err != nil {
+		return "", errors.New("property not found for numbering")
+	}
+	fy := financialYear(date)
+
+	// 1. Make sure the series exists; a concurrent first insert is not an error
+	series := models.NumberSeries{PropertyID: propertyID, Kind: kind, FinancialYear: fy}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&series).Error; err != nil {
+		return "", err
+	}
+
+	// 2. Lock it, bump it
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("property_id = ? AND kind = ? AND financial_year = ?", propertyID, kind, fy).
+		First(&series).Error; err != nil {
+		return "", err
+	}
+	series.LastNumber++
+	if err := tx.Model(&series).Update("last_number", series.LastNumber).Error; err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s/%s/%06d", seriesPrefix(property, kind), fy, series.LastNumber), nil
+}
+
+// UpdateNumberingPrefixes changes the prefixes for new receipts and invoices; issued numbers keep theirs
+func UpdateNumberingPrefixes(propertyID, ownerID uint, receiptPrefix, invoicePrefix string) error {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return err
+	}
+
+	receiptPrefix = strings.ToUpper(strings.TrimSpace(receiptPrefix))
+	invoicePrefix = strings.ToUpper(strings.TrimSpace(invoicePrefix))
+	for _, prefix := range []string{receiptPrefix, invoicePrefix} {
+		if prefix != "" && !seriesPrefixPattern.MatchString(prefix) {
+			return fmt.Errorf("invalid prefix %q: use up to 16 letters, digits, '/' or '-'", prefix)
+		}
+	}
+
+	// Compare the prefixes that will actually be printed, so a custom one cannot collide with a default
+	var property models.Property
+	if err := config.DB.First(&property, propertyID).Error; err != nil {
+		return errors.New("property not found")
+	}
+	property.ReceiptPrefix, property.InvoicePrefix = receiptPrefix, invoicePrefix
+	if seriesPrefix(property, "receipt") == seriesPrefix(property, "invoice") {
+		return errors.New("invalid prefix: receipts and invoices need different prefixes")
+	}
+
+	return config.DB.Model(&models.Property{}).Where("id = ?", propertyID).Updates(map[string]interface{}{
+		"receipt_prefix": receiptPrefix,
+		"invoice_prefix": invoicePrefix,
+	}).Error
+}
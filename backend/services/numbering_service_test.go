@@ -0,0 +1,29 @@
+package services
+
+import (
+	"pg-manager-backend/models"
+	"testing"
+)
+
+func TestNumberingPrefixesMustDifferOnceDefaultsApply(t *testing.T) {
+	requireDB(t)
+	property, _ := createTestTenant(t, models.TenantProfile{Name: "Ravi"})
+
+	// "Sri Sai PG" prints receipts as SRI/... by default, so invoices cannot take SRI
+	cases := []struct {
+		receipt, invoice string
+		ok               bool
+	}{
+		{"", "SRI", false},
+		{"SRI/INV", "", false},
+		{"RCP", "RCP", false},
+		{"", "SRIINV", true},
+		{"RCP", "", true},
+	}
+	for _, tc := range cases {
+		err := UpdateNumberingPrefixes(property.ID, property.OwnerID, tc.receipt, tc.invoice)
+		if (err == nil) != tc.ok {
+			t.Errorf("prefixes %q/%q: err = %v, want ok=%v", tc.receipt, tc.invoice, err, tc.ok)
+		}
+	}
+}
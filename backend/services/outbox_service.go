@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"math/rand"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"pg-manager-backend/notifier"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// A claimed message is retried by another worker if nothing is heard back within this lease
+	outboxLease       = 5 * time.Minute
+	outboxPollEvery   = 5 * time.Second
+	outboxBaseBackoff = 30 * time.Second
+	outboxMaxBackoff  = time.Hour
+)
+
+// outboxWake nudges idle workers so freshly queued messages go out without waiting for the next poll
+var outboxWake = make(chan struct{}, 1)
+
+// enqueueMessage stores a message for the outbox workers; it survives restarts and provider outages
+func enqueueMessage(msg notifier.Message, propertyID, tenantID uint, kind string) (models.OutboundMessage, error) {
+	return enqueueMessageAt(msg, propertyID, tenantID, kind, time.Now())
+}
+
+// enqueueMessageAt holds the message back until sendAt, e.g. to pace a broadcast
+func enqueueMessageAt(msg notifier.Message, propertyID, tenantID uint, kind string, sendAt time.Time) (models.OutboundMessage, error) {
+	record := models.OutboundMessage{
+		PropertyID:    propertyID,
+		TenantID:      tenantID,
+		Kind:          kind,
+		Channel:       msg.Channel,
+		Recipient:     msg.To,
+		Subject:       msg.Subject,
+		Body:          msg.Body,
+		TemplateSID:   msg.TemplateSID,
+		Status:        "pending",
+		NextAttemptAt: sendAt,
+	}
+	if len(msg.TemplateVars) > 0 {
+		vars, _ := json.Marshal(msg.TemplateVars)
+		record.TemplateVars = string(vars)
+	}
+	if err := config.DB.Create(&record).Error; err != nil {
+		return record, err
+	}
+
+	select {
+	case outboxWake <- struct{}{}:
+	default:
+	}
+	return record, nil
+}
+
+// StartOutboxWorkers launches the delivery pool; call once from main after the DB is connected
+func StartOutboxWorkers(ctx context.Context, workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		go outboxWorker(ctx)
+	}
+	log.Printf("📬 Outbox started with %d worker(s)", workers)
+}
+
+func outboxWorker(ctx context.Context) {
+	ticker := time.NewTicker(outboxPollEvery)
+	defer ticker.Stop()
+
+	for {
+		// Drain everything that is due, then wait
+		for {
+			msg, err := claimNextMessage()
+			if err != nil {
+				if !errors.Is(err, gorm.ErrRecordNotFound) {
+					log.Printf("⚠️ Outbox claim failed: %v", err)
+				}
+				break
+			}
+			deliverMessage(msg)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-outboxWake:
+		}
+	}
+}
+
+// claimNextMessage locks one due message (skipping rows other workers hold) and leases it. A lease
+// that ran out on the last allowed attempt means the send itself keeps killing the worker, so that
+// message is dead-lettered instead of being claimed again.
+func claimNextMessage() (models.OutboundMessage, error) {
+	for {
+		var msg models.OutboundMessage
+		abandoned := false
+		now := time.Now()
+
+		err := config.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("status IN ? AND next_attempt_at <= ?", []string{"pending", "sending"}, now).
+				Order("next_attempt_at asc, id asc").
+				First(&msg).Error; err != nil {
+				return err
+			}
+
+			if msg.Status == "sending" && msg.Attempts >= config.App.OutboxMaxAttempts {
+				abandoned = true
+				msg.Status = "dead"
+				return tx.Model(&msg).Updates(map[string]interface{}{
+					"status":     "dead",
+					"last_error": "delivery never finished: the worker stopped while sending",
+				}).Error
+			}
+
+			msg.Attempts++
+			return tx.Model(&msg).Updates(map[string]interface{}{
+				"status":          "sending",
+				"attempts":        msg.Attempts,
+				"next_attempt_at": now.Add(outboxLease),
+			}).Error
+		})
+		if err != nil || !abandoned {
+			return msg, err
+		}
+
+		log.Printf("☠️ Message %d dead-lettered after %d attempts: lease expired while sending", msg.ID, msg.Attempts)
+		fallbackToNextChannel(msg)
+	}
+}
+
+// deliverMessage sends one claimed message and records the outcome
+func deliverMessage(msg models.OutboundMessage) {
+	outgoing := notifier.Message{
+		Channel:     msg.Channel,
+		To:          msg.Recipient,
+		Subject:     msg.Subject,
+		Body:        msg.Body,
+		TemplateSID: msg.TemplateSID,
+	}
+	if msg.TemplateVars != "" {
+		json.Unmarshal([]byte(msg.TemplateVars), &outgoing.TemplateVars)
+	}
+
+	providerID, err := notifier.Send(outgoing)
+
+	if err == nil {
+		now := time.Now()
+		config.DB.Model(&msg).Updates(map[string]interface{}{
+			"status":      "sent",
+			"provider_id": providerID,
+			"sent_at":     &now,
+			"last_error":  "",
+		})
+		return
+	}
+
+	updates := map[string]interface{}{"last_error": err.Error()}
+	if msg.Attempts >= config.App.OutboxMaxAttempts {
+		updates["status"] = "dead"
+		log.Printf("☠️ Message %d dead-lettered after %d attempts: %v", msg.ID, msg.Attempts, err)
+	} else {
+		updates["status"] = "pending"
+		updates["next_attempt_at"] = time.Now().Add(outboxBackoff(msg.Attempts))
+		log.Printf("⚠️ Message %d attempt %d failed, will retry: %v", msg.ID, msg.Attempts, err)
+	}
+	config.DB.Model(&msg).Updates(updates)
+
+	if updates["status"] == "dead" {
+		fallbackToNextChannel(msg)
+	}
+}
+
+// outboxBackoff doubles the wait after each failure (30s, 1m, 2m, ...) with jitter, capped at an hour
+func outboxBackoff(attempts int) time.Duration {
+	wait := outboxBaseBackoff << (attempts - 1)
+	if wait > outboxMaxBackoff || wait <= 0 {
+		wait = outboxMaxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(wait) / 5))
+	return wait + jitter
+}
+
+// GetOutboundMessages lists queued and sent messages for an owner's property, newest first
+func GetOutboundMessages(propertyID, ownerID uint, status string) ([]models.OutboundMessage, error) {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return nil, err
+	}
+
+	var messages []models.OutboundMessage
+	q := config.DB.Where("property_id = ?", propertyID)
+	if status != "" {
+		q = q.Where("status = ?", status)
+	}
+	if err := q.Order("created_at desc").Limit(200).Find(&messages).Error; err != nil {
+		return nil, err
+	}
+
+	// The owner must not be able to read a tenant's verification code and complete it for them
+	for i := range messages {
+		if messages[i].Kind == "otp" {
+			messages[i].Body = "[verification code hidden]"
+			messages[i].TemplateVars = ""
+		}
+	}
+	return messages, nil
+}
+
+// RetryOutboundMessage puts a dead-lettered message back in the queue with a fresh attempt count
+func RetryOutboundMessage(messageID, ownerID uint) error {
+	var msg models.OutboundMessage
+	if err := config.DB.First(&msg, messageID).Error; err != nil {
+		return errors.New("message not found")
+	}
+	if err := verifyPropertyOwner(msg.PropertyID, ownerID); err != nil {
+		return err
+	}
+	if msg.Status != "dead" {
+		return errors.New("only dead-lettered messages can be retried")
+	}
+
+	err := config.DB.Model(&msg).Updates(map[string]interface{}{
+		"status":          "pending",
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+	}).Error
+	if err == nil {
+		select {
+		case outboxWake <- struct{}{}:
+		default:
+		}
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"errors"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestExpiredLeaseOnLastAttemptIsDeadLettered(t *testing.T) {
+	requireDB(t)
+	stuck := models.OutboundMessage{
+		Kind: "rent_due", Channel: "whatsapp", Recipient: "+919845012345", Body: "Rent is due",
+		Status: "sending", Attempts: config.App.OutboxMaxAttempts, NextAttemptAt: time.Now().Add(-time.Minute),
+	}
+	config.DB.Create(&stuck)
+
+	if _, err := claimNextMessage(); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("claimed a message that used up its attempts: %v", err)
+	}
+	config.DB.First(&stuck, stuck.ID)
+	if stuck.Status != "dead" || stuck.Attempts != config.App.OutboxMaxAttempts {
+		t.Errorf("status %s after %d attempts, want dead after %d", stuck.Status, stuck.Attempts, config.App.OutboxMaxAttempts)
+	}
+}
+
+func TestOutboundMessagesHideVerificationCodes(t *testing.T) {
+	requireDB(t)
+	property, tenant := createTestTenant(t, models.TenantProfile{Name: "Ravi"})
+	notifyTenant(tenant, "otp", map[string]interface{}{"OTP": "482913"})
+	notifyTenant(tenant, "payment_received", map[string]interface{}{"Amount": 1500.0, "Method": "UPI", "Balance": 0.0})
+
+	messages, err := GetOutboundMessages(property.ID, property.OwnerID, "")
+	if err != nil {
+		t.Fatalf("GetOutboundMessages: %v", err)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("listed %d messages, want 2", len(messages))
+	}
+	for _, msg := range messages {
+		if msg.Kind == "otp" && (msg.Body != "[verification code hidden]" || msg.TemplateVars != "") {
+			t.Errorf("OTP message shown to the owner: %q %q", msg.Body, msg.TemplateVars)
+		}
+		if msg.Kind == "payment_received" && msg.Body == "[verification code hidden]" {
+			t.Error("a payment message was hidden too")
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"pg-manager-backend/notifier"
+	"strings"
+	"time"
+)
+
+// Owner alert events; each maps to a switch in OwnerAlertSettings
+const (
+	AlertNewComplaint    = "new_complaint"
+	AlertPaymentReceived = "payment_received"
+	AlertPaymentFailed   = "payment_failed"
+	AlertTenantOverdue   = "tenant_overdue"
+	AlertRoomVacated     = "room_vacated"
+	AlertDailyDigest     = "daily_digest"
+)
+
+// getOwnerAlertSettings loads the owner's settings, creating the all-on defaults on first use
+func getOwnerAlertSettings(ownerID uint) models.OwnerAlertSettings {
+	settings := models.OwnerAlertSettings{OwnerID: ownerID}
+	config.DB.Where("owner_id = ?", ownerID).FirstOrCreate(&settings)
+	return settings
+}
+
+func alertEnabled(settings models.OwnerAlertSettings, event string) bool {
+	switch event {
+	case AlertNewComplaint:
+		return settings.NewComplaint
+	case AlertPaymentReceived:
+		return settings.PaymentReceived
+	case AlertPaymentFailed:
+		return settings.PaymentFailed
+	case AlertTenantOverdue:
+		return settings.TenantOverdue
+	case AlertRoomVacated:
+		return settings.RoomVacated
+	case AlertDailyDigest:
+		return settings.DailyDigest
+	}
+	return true
+}
+
+// GetOwnerAlertSettings returns the owner's alert switches
+func GetOwnerAlertSettings(ownerID uint) models.OwnerAlertSettings {
+	return getOwnerAlertSettings(ownerID)
+}
+
+// UpdateOwnerAlertSettings replaces every switch, including ones turned off
+func UpdateOwnerAlertSettings(ownerID uint, input models.OwnerAlertSettings) (models.OwnerAlertSettings, error) {
+	if input.Channel != notifier.WhatsApp && input.Channel != notifier.Email {
+		return models.OwnerAlertSettings{}, errors.New("channel must be whatsapp or email")
+	}
+	if input.OverdueDays < 1 || input.OverdueDays > 90 {
+		return models.OwnerAlertSettings{}, errors.New("overdue_days must be between 1 and 90")
+	}
+
+	settings := getOwnerAlertSettings(ownerID)
+	err := config.DB.Model(&settings).Updates(map[string]interface{}{
+		"channel":          input.Channel,
+		"new_complaint":    input.NewComplaint,
+		"payment_received": input.PaymentReceived,
+		"payment_failed":   input.PaymentFailed,
+		"tenant_overdue":   input.TenantOverdue,
+		"overdue_days":     input.OverdueDays,
+		"room_vacated":     input.RoomVacated,
+		"daily_digest":     input.DailyDigest,
+	}).Error
+	if err != nil {
+		return models.OwnerAlertSettings{}, errors.New("could not save alert settings")
+	}
+	return getOwnerAlertSettings(ownerID), nil
+}
+
+// notifyOwner queues a message on the owner's alert channel. Email needs an address on file;
+// otherwise it falls back to WhatsApp on the owner's phone.
+func notifyOwner(owner models.User, propertyID uint, kind, subject, body string) error {
+	settings := getOwnerAlertSettings(owner.ID)
+
+	msg := notifier.Message{Channel: notifier.WhatsApp, To: owner.Phone, Subject: subject, Body: body}
+	if settings.Channel == notifier.Email && owner.Email != nil && *owner.Email != "" {
+		msg.Channel = notifier.Email
+		msg.To = *owner.Email
+	}
+
+	if _, err := enqueueMessage(msg, propertyID, 0, kind); err != nil {
+		log.Printf("⚠️ Could not queue %s for owner %s: %v", kind, owner.Name, err)
+		return err
+	}
+	return nil
+}
+
+// alertOwner tells the property's owner about an event, if they have that alert switched on
+func alertOwner(propertyID uint, event, subject, body string) {
+	var property models.Property
+	if err := config.DB.First(&property, propertyID).Error; err != nil {
+		return
+	}
+	var owner models.User
+	if err := config.DB.First(&owner, property.OwnerID).Error; err != nil {
+		return
+	}
+	if !alertEnabled(getOwnerAlertSettings(owner.ID), event) {
+		return
+	}
+
+	notifyOwner(owner, propertyID, "owner_"+event, subject, fmt.Sprintf("🏠 %s\n%s", property.Name, body))
+}
+
+// ProcessOverdueAlerts tells owners about tenants with an unpaid invoice past the owner's overdue threshold.
+// Each invoice is reported once, and a skipped or failed run is caught up on the next one.
+// A non-zero ownerID limits the run to that owner's properties.
+func ProcessOverdueAlerts(ownerID uint) error {
+	var properties []models.Property
+	if err := ownerScope(config.DB, "owner_id", ownerID).Find(&properties).Error; err != nil {
+		return err
+	}
+
+	alerted := 0
+	for _, property := range properties {
+		settings := getOwnerAlertSettings(property.OwnerID)
+		if !settings.TenantOverdue {
+			continue
+		}
+
+		// Invoices past the threshold the owner has not been told about yet
+		cutoff := dateOnly(time.Now()).AddDate(0, 0, -settings.OverdueDays)
+		var invoices []models.Invoice
+		config.DB.Select("id, tenant_id").
+			Where("property_id = ? AND status IN ? AND due_date <= ? AND owner_alerted_at IS NULL", property.ID, []string{"open", "partial"}, cutoff).
+			Find(&invoices)
+		if len(invoices) == 0 {
+			continue
+		}
+		invoiceIDs := make([]uint, len(invoices))
+		newlyOverdue := map[uint]bool{}
+		for i, invoice := range invoices {
+			invoiceIDs[i] = invoice.ID
+			newlyOverdue[invoice.TenantID] = true
+		}
+
+		buckets, err := GetOverdueBuckets(property.ID, property.OwnerID)
+		if err != nil {
+			continue
+		}
+
+		var lines []string
+		for _, bucket := range buckets {
+			for _, tenant := range bucket.Tenants {
+				if newlyOverdue[tenant.TenantID] {
+					lines = append(lines, fmt.Sprintf("• %s (Room %s) — ₹%.2f, %d days", tenant.TenantName, tenant.RoomNo, tenant.AmountOverdue, tenant.DaysOverdue))
+				}
+			}
+		}
+		if len(lines) > 0 {
+			alertOwner(property.ID, AlertTenantOverdue, "Tenants Overdue",
+				fmt.Sprintf("⚠️ %d tenant(s) are %d+ days overdue:\n%s", len(lines), settings.OverdueDays, strings.Join(lines, "\n")))
+			alerted += len(lines)
+		}
+
+		// Offboarded tenants' invoices are marked too, so they are never reported later
+		config.DB.Model(&models.Invoice{}).Where("id IN ?", invoiceIDs).Update("owner_alerted_at", time.Now())
+	}
+
+	log.Printf("🔔 Overdue alerts: %d tenants reported", alerted)
+	return nil
+}
+
+// SendOwnerDailyDigests sends each owner (or only ownerID) today's collections, current dues and open complaints per property
+func SendOwnerDailyDigests(ownerID uint) error {
+	from := dateOnly(time.Now())
+	to := from.AddDate(0, 0, 1)
+
+	ownerIDs, err := jobOwnerIDs(ownerID)
+	if err != nil {
+		return err
+	}
+
+	sent := 0
+	for _, ownerID := range ownerIDs {
+		if !getOwnerAlertSettings(ownerID).DailyDigest {
+			continue
+		}
+		var owner models.User
+		if err := config.DB.First(&owner, ownerID).Error; err != nil {
+			continue
+		}
+		properties, _ := GetAllProperties(ownerID)
+
+		var body strings.Builder
+		body.WriteString(fmt.Sprintf("🌙 Daily Digest — %s\n", from.Format("02 Jan 2006")))
+		for _, property := range properties {
+			s := summarizeProperty(property, from, to)
+
+			var newComplaints int64
+			config.DB.Model(&models.Complaint{}).Where("property_id = ? AND created_at >= ?", property.ID, from).Count(&newComplaints)
+
+			body.WriteString(fmt.Sprintf("\n🏠 %s\nCollected today: ₹%.2f\nOutstanding Dues: ₹%.2f\nOpen Complaints: %d (%d new today, %d past SLA)\n",
+				s.PropertyName, s.Collected, s.OutstandingDue, s.OpenComplaints, newComplaints, countOverdueComplaints(property.ID)))
+		}
+
+		if notifyOwner(owner, 0, "owner_daily_digest", "Daily Digest — "+from.Format("02 Jan 2006"), body.String()) == nil {
+			sent++
+		}
+	}
+
+	log.Printf("🌙 Daily digests queued for %d owners", sent)
+	return nil
+}
@@ -1,16 +1,20 @@
 package services
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"log"
 	"pg-manager-backend/config"
 	"pg-manager-backend/models"
-	"pg-manager-backend/utils"
 	"time"
-	// "pg-manager-backend/utils"
+
+	"gorm.io/gorm"
 )
 
-// RecordManualPayment handles Cash, UPI, or Bank transfers recorded by the owner
-func RecordManualPayment(userID uint, amount float64, method string) (float64, error) {
+// RecordManualPayment handles Cash, UPI, or Bank transfers recorded by the owner.
+// invoiceIDs optionally picks which invoices are settled first; otherwise the oldest are.
+func RecordManualPayment(userID uint, amount float64, method string, invoiceIDs []uint) (float64, error) {
 	tx := config.DB.Begin()
 
 	var profile models.TenantProfile
@@ -19,23 +23,14 @@ func RecordManualPayment(userID uint, amount float64, method string) (float64, e
 		return 0, err
 	}
 
-	// Balance logic
-	newBalance := profile.Balance - amount
-	if err := tx.Model(&profile).Update("balance", newBalance).Error; err != nil {
-		tx.Rollback()
-		return 0, err
-	}
-
 	paymentRecord := models.Payment{
-		TenantID:    profile.UserID,
-		PropertyID:  profile.PropertyID,
 		Amount:      amount,
 		PaymentType: "Manual-Payment",
 		Method:      method,
-		Date:        time.Now(),
 	}
 
-	if err := tx.Create(&paymentRecord).Error; err != nil {
+	newBalance, err := applyPayment(tx, &profile, &paymentRecord, invoiceIDs)
+	if err != nil {
 		tx.Rollback()
 		return 0, err
 	}
@@ -44,51 +39,182 @@ func RecordManualPayment(userID uint, amount float64, method string) (float64, e
 		return 0, err
 	}
 
-	// --- NEW: Generate Receipt & Send WhatsApp ---
-	go func() {
-		fileName, err := utils.GenerateReceipt(paymentRecord, profile.Name)
-		if err == nil {
-			receiptURL := config.App.BaseURL + "/receipts/" + fileName
-			msg := fmt.Sprintf("✅ Payment Received!\n\nNamaste %s, we received ₹%.2f via %s.\nNew Balance: ₹%.2f\nDownload Receipt: %s",
-				profile.Name, amount, method, newBalance, receiptURL)
+	// --- NEW: Generate Receipt & queue the confirmation ---
+	sendPaymentConfirmation(paymentRecord, profile, newBalance)
 
-			utils.SendWhatsAppMessage(profile.PhoneNumber, msg)
-		}
-	}()
+	return newBalance, nil
+}
 
+// applyPayment debits the tenant balance, writes the payment row and allocates it to invoices.
+// It must run inside the caller's transaction; TenantID/PropertyID/Date are filled in here.
+func applyPayment(tx *gorm.DB, profile *models.TenantProfile, payment *models.Payment, allocateTo []uint) (float64, error) {
+	// Balance logic
+	newBalance := profile.Balance - payment.Amount
+	if err := tx.Model(profile).Update("balance", newBalance).Error; err != nil {
+		return 0, err
+	}
+	if err := recordPayment(tx, profile, payment, newBalance, allocateTo); err != nil {
+		return 0, err
+	}
 	return newBalance, nil
 }
 
+// recordPayment numbers and saves a payment without touching the rent balance, e.g. a deposit refund
+func recordPayment(tx *gorm.DB, profile *models.TenantProfile, payment *models.Payment, balanceAfter float64, allocateTo []uint) error {
+	payment.TenantID = profile.UserID
+	payment.PropertyID = profile.PropertyID
+	if payment.Date.IsZero() {
+		payment.Date = time.Now()
+	}
+	payment.BalanceAfter = &balanceAfter
+
+	receiptNo, err := nextDocumentNumber(tx, payment.PropertyID, "receipt", payment.Date)
+	if err != nil {
+		return err
+	}
+	payment.ReceiptNo = receiptNo
+
+	if err := tx.Create(payment).Error; err != nil {
+		return err
+	}
+
+	// Reversals and refunds (negative amounts) are never allocated
+	return allocatePayment(tx, payment, allocateTo)
+}
+
+// sendPaymentConfirmation generates the receipt and queues it on the tenant's preferred channel.
+// The message is queued even if the receipt fails, so the tenant still hears about the payment.
+func sendPaymentConfirmation(payment models.Payment, profile models.TenantProfile, newBalance float64) {
+	data := map[string]interface{}{
+		"Amount":  payment.Amount,
+		"Method":  payment.Method,
+		"Balance": newBalance,
+	}
+
+	key, err := renderReceipt(payment, profile.Name)
+	if err == nil {
+		data["ReceiptURL"] = downloadURL(key)
+	} else {
+		log.Printf("⚠️ Receipt Generation Failed: %v", err)
+	}
+
+	notifyTenant(profile, "payment_received", data)
+}
+
 type PaymentResponse struct {
 	models.Payment
 	TenantName string `json:"tenant_name"`
 }
 
-func GetAllPaymentHistory() ([]PaymentResponse, error) {
-	var results []PaymentResponse
+// PaymentHistoryFilter narrows /payments; zero values mean "no filter"
+type PaymentHistoryFilter struct {
+	PropertyID  uint
+	TenantID    uint
+	From        *time.Time
+	To          *time.Time // Inclusive: the whole day is covered
+	Method      string
+	PaymentType string
+	Cursor      string
+	Limit       int
+}
 
-	// Get all payments
-	config.DB.Table("payments").Order("date desc").Scan(&results)
+// PaymentHistoryPage is one page of history plus totals for the whole filtered set
+type PaymentHistoryPage struct {
+	Items       []PaymentResponse `json:"items"`
+	NextCursor  string            `json:"next_cursor"`
+	TotalCount  int64             `json:"total_count"`
+	TotalAmount float64           `json:"total_amount"`
+}
+
+// GetPaymentHistory returns the caller's payments only, newest first, in a single joined query per page
+func GetPaymentHistory(ownerID uint, filter PaymentHistoryFilter) (PaymentHistoryPage, error) {
+	page := PaymentHistoryPage{Items: []PaymentResponse{}}
+
+	if filter.Limit <= 0 || filter.Limit > 200 {
+		filter.Limit = 50
+	}
 
-	for i := range results {
-		var name string
-		// Try active table first
-		config.DB.Table("tenant_profiles").
-			Where("user_id = ?", results[i].TenantID).
-			Select("name").Scan(&name)
+	// 1. Scope + filters, shared by the totals and the page query
+	scoped := func() *gorm.DB {
+		q := config.DB.Table("payments").
+			Where("payments.property_id IN (?)", config.DB.Model(&models.Property{}).Select("id").Where("owner_id = ?", ownerID))
 
-		// If not found, check the backup table
-		if name == "" {
-			config.DB.Table("archived_tenants").
-				Where("original_user_id = ?", results[i].TenantID).
-				Select("name").Scan(&name)
+		if filter.PropertyID != 0 {
+			q = q.Where("payments.property_id = ?", filter.PropertyID)
 		}
+		if filter.TenantID != 0 {
+			q = q.Where("payments.tenant_id = ?", filter.TenantID)
+		}
+		if filter.From != nil {
+			q = q.Where("payments.date >= ?", *filter.From)
+		}
+		if filter.To != nil {
+			q = q.Where("payments.date < ?", filter.To.AddDate(0, 0, 1))
+		}
+		if filter.Method != "" {
+			q = q.Where("payments.method = ?", filter.Method)
+		}
+		if filter.PaymentType != "" {
+			q = q.Where("payments.payment_type = ?", filter.PaymentType)
+		}
+		return q
+	}
 
-		if name != "" {
-			results[i].TenantName = name
-		} else {
-			results[i].TenantName = "Unknown (ID: " + fmt.Sprint(results[i].TenantID) + ")"
+	// 2. Totals for the filtered set (not just this page)
+	var totals struct {
+		Count  int64
+		Amount float64
+	}
+	if err := scoped().Select("COUNT(*) AS count, COALESCE(SUM(payments.amount), 0) AS amount").Scan(&totals).Error; err != nil {
+		return page, err
+	}
+	page.TotalCount = totals.Count
+	page.TotalAmount = totals.Amount
+
+	// 3. Keyset pagination on (date, id) so pages stay stable while new payments arrive
+	q := scoped()
+	if filter.Cursor != "" {
+		cursorDate, cursorID, err := decodePaymentCursor(filter.Cursor)
+		if err != nil {
+			return page, err
 		}
+		q = q.Where("(payments.date < ? OR (payments.date = ? AND payments.id < ?))", cursorDate, cursorDate, cursorID)
+	}
+
+	// Name comes from the active profile, falling back to the archive for offboarded tenants
+	err := q.Select("payments.*, COALESCE(tenant_profiles.name, archived_tenants.name, 'Unknown (ID: ' || payments.tenant_id || ')') AS tenant_name").
+		Joins("LEFT JOIN tenant_profiles ON tenant_profiles.user_id = payments.tenant_id AND tenant_profiles.deleted_at IS NULL").
+		Joins("LEFT JOIN archived_tenants ON archived_tenants.original_user_id = payments.tenant_id").
+		Order("payments.date desc, payments.id desc").
+		Limit(filter.Limit + 1).
+		Scan(&page.Items).Error
+	if err != nil {
+		return page, err
+	}
+
+	if len(page.Items) > filter.Limit {
+		page.Items = page.Items[:filter.Limit]
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor = encodePaymentCursor(last.Date, last.ID)
+	}
+	return page, nil
+}
+
+func encodePaymentCursor(date time.Time, id uint) string {
+	raw := fmt.Sprintf("%d:%d", date.UnixNano(), id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodePaymentCursor(cursor string) (time.Time, uint, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, errors.New("invalid cursor")
+	}
+
+	var nanos int64
+	var id uint
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &nanos, &id); err != nil {
+		return time.Time{}, 0, errors.New("invalid cursor")
 	}
-	return results, nil
+	return time.Unix(0, nanos), id, nil
 }
@@ -4,6 +4,10 @@ import (
 	"errors"
 	"pg-manager-backend/config"
 	"pg-manager-backend/models"
+	"pg-manager-backend/utils"
+	"strconv"
+
+	"gorm.io/gorm"
 )
 
 // CreateProperty logic remains the same
@@ -19,6 +23,53 @@ func CreateProperty(name, address string, ownerID uint) (models.Property, error)
 	return property, nil
 }
 
+// ownerScope limits a background job's query to one owner's properties; ownerID 0 is a scheduled run
+// covering every owner. column is "owner_id" for the properties table, else the table's property column.
+func ownerScope(query *gorm.DB, column string, ownerID uint) *gorm.DB {
+	if ownerID == 0 {
+		return query
+	}
+	if column == "owner_id" {
+		return query.Where("owner_id = ?", ownerID)
+	}
+	return query.Where(column+" IN (?)", config.DB.Model(&models.Property{}).Select("id").Where("owner_id = ?", ownerID))
+}
+
+// jobOwnerIDs lists the owners a per-owner job (digest, report) should cover
+func jobOwnerIDs(ownerID uint) ([]uint, error) {
+	if ownerID != 0 {
+		return []uint{ownerID}, nil
+	}
+	var ownerIDs []uint
+	err := config.DB.Model(&models.Property{}).Distinct("owner_id").Pluck("owner_id", &ownerIDs).Error
+	return ownerIDs, err
+}
+
+// verifyPropertyOwner is the shared ownership check for owner-only property operations
+func verifyPropertyOwner(propertyID, ownerID uint) error {
+	var count int64
+	config.DB.Model(&models.Property{}).Where("id = ? AND owner_id = ?", propertyID, ownerID).Count(&count)
+	if count == 0 {
+		return errors.New("unauthorized: you do not own this property")
+	}
+	return nil
+}
+
+// UpdatePropertyUPI sets the VPA that invoices and reminders ask tenants to pay
+func UpdatePropertyUPI(propertyID, ownerID uint, vpa, payeeName string) error {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return err
+	}
+	if vpa != "" && !utils.IsValidVPA(vpa) {
+		return errors.New("invalid UPI VPA: expected format name@bank")
+	}
+
+	return config.DB.Model(&models.Property{}).Where("id = ?", propertyID).Updates(map[string]interface{}{
+		"upi_vpa":        vpa,
+		"upi_payee_name": payeeName,
+	}).Error
+}
+
 // GetPropertyStats - UPDATED to include expenditure
 func GetPropertyStats(propertyID string) (map[string]interface{}, error) {
 	var roomCount, tenantCount, complaintCount int64
@@ -32,11 +83,9 @@ func GetPropertyStats(propertyID string) (map[string]interface{}, error) {
 		Where("rooms.property_id = ? AND tenant_profiles.status = ?", propertyID, "active").
 		Count(&tenantCount)
 
-	// 3. Pending Complaints
-	config.DB.Table("complaints").
-		Joins("JOIN tenant_profiles ON tenant_profiles.user_id = complaints.tenant_id").
-		Joins("JOIN rooms ON rooms.id = tenant_profiles.room_id").
-		Where("rooms.property_id = ? AND complaints.status = ?", propertyID, "pending").
+	// 3. Unresolved Complaints
+	config.DB.Model(&models.Complaint{}).
+		Where("property_id = ? AND status IN ?", propertyID, openComplaintStatuses).
 		Count(&complaintCount)
 
 	// 4. ADDED: Monthly Expenditure logic
@@ -55,7 +104,7 @@ func GetPropertyStats(propertyID string) (map[string]interface{}, error) {
 
 // GetDashboardData (Global Stats) remains same
 func GetDashboardData(propertyID string) (map[string]interface{}, error) {
-	var totalRooms, activeTenants, pendingIssues int64
+	var totalRooms, activeTenants, pendingIssues, overdueIssues int64
 	var totalRevenue, totalExpenditure float64
 
 	// 1. Total Rooms
@@ -67,8 +116,11 @@ func GetDashboardData(propertyID string) (map[string]interface{}, error) {
 	// 3. UPDATED: Pending Complaints - QR System simplified! 🚀
 	// Since we added property_id directly to the Complaint model, no JOIN is needed.
 	config.DB.Model(&models.Complaint{}).
-		Where("property_id = ? AND status = ?", propertyID, "Pending").
+		Where("property_id = ? AND status IN ?", propertyID, openComplaintStatuses).
 		Count(&pendingIssues)
+	if id, err := strconv.ParseUint(propertyID, 10, 64); err == nil {
+		overdueIssues = countOverdueComplaints(uint(id))
+	}
 
 	// 4. Total Revenue (Manual + Online)
 	config.DB.Model(&models.Payment{}).
@@ -86,6 +138,7 @@ func GetDashboardData(propertyID string) (map[string]interface{}, error) {
 		"total_rooms":       totalRooms,
 		"active_tenants":    activeTenants,
 		"pending_issues":    pendingIssues, // Matches pending_issues in Vue
+		"overdue_issues":    overdueIssues, // Past their SLA deadline
 		"total_revenue":     totalRevenue,
 		"total_expenditure": totalExpenditure,
 	}, nil
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"pg-manager-backend/utils"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// QR forms a token can open; the tree only has the complaint form so far
+const qrFormComplaint = "complaint"
+
+// QRTarget is what a scanned token points at
+type QRTarget struct {
+	Form     string
+	Property models.Property
+	Room     *models.Room // Nil on the property-wide poster
+}
+
+// PublicForm is what the public form needs before the tenant types anything
+type PublicForm struct {
+	PropertyName string   `json:"property_name"`
+	RoomNo       string   `json:"room_no,omitempty"` // Set when the QR was printed for one room
+	Categories   []string `json:"categories"`
+}
+
+func qrSignature(payload string) string {
+	mac := hmac.New(sha256.New, []byte(config.App.JWTSecret))
+	mac.Write([]byte("qr:" + payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:12])
+}
+
+// qrToken is "<payload>.<signature>", where the payload names the form, property, room (0 = any) and the
+// property's QR version. Rotating the version voids every poster printed before.
+func qrToken(form string, property models.Property, roomID uint) string {
+	payload := fmt.Sprintf("%s.%d.%d.%d", form, property.ID, roomID, property.QRVersion)
+	return base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." + qrSignature(payload)
+}
+
+// ResolveQRToken checks a scanned token and loads the property (and room) it was printed for
+func ResolveQRToken(token string) (QRTarget, error) {
+	invalid := errors.New("invalid qr code")
+
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return QRTarget{}, invalid
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return QRTarget{}, invalid
+	}
+	payload := string(raw)
+	if !hmac.Equal([]byte(signature), []byte(qrSignature(payload))) {
+		return QRTarget{}, invalid
+	}
+
+	var target QRTarget
+	var propertyID, roomID uint
+	var version int
+	parts := strings.SplitN(payload, ".", 2)
+	if len(parts) != 2 {
+		return QRTarget{}, invalid
+	}
+	target.Form = parts[0]
+	if _, err := fmt.Sscanf(parts[1], "%d.%d.%d", &propertyID, &roomID, &version); err != nil {
+		return QRTarget{}, invalid
+	}
+
+	// A deleted property, a rotated version or a room that has since been removed all void the code
+	if err := config.DB.First(&target.Property, propertyID).Error; err != nil || target.Property.QRVersion != version {
+		return QRTarget{}, invalid
+	}
+	if roomID != 0 {
+		var room models.Room
+		if err := config.DB.Where("id = ? AND property_id = ?", roomID, propertyID).First(&room).Error; err != nil {
+			return QRTarget{}, invalid
+		}
+		target.Room = &room
+	}
+	return target, nil
+}
+
+// qrFormURL is the page a poster's QR code opens
+func qrFormURL(token string) string {
+	return config.App.FrontendURL + "/help/" + token
+}
+
+// GetPublicForm returns what the complaint form shows for a scanned token
+func GetPublicForm(token string) (PublicForm, error) {
+	target, err := ResolveQRToken(token)
+	if err != nil || target.Form != qrFormComplaint {
+		return PublicForm{}, errors.New("invalid qr code")
+	}
+	form := PublicForm{PropertyName: target.Property.Name, Categories: complaintCategories}
+	if target.Room != nil {
+		form.RoomNo = target.Room.RoomNumber
+	}
+	return form, nil
+}
+
+// CheckQRComplaint prepares a complaint from the public form. The token decides the property; a room
+// poster also fixes the room, while the property-wide poster needs the tenant to type theirs. The phone
+// and room must belong to a tenant of that property, so the form can refuse strangers before it reads
+// any attachments. Pass the result to RegisterComplaint.
+func CheckQRComplaint(token string, data models.Complaint) (models.Complaint, error) {
+	target, err := ResolveQRToken(token)
+	if err != nil || target.Form != qrFormComplaint {
+		return data, errors.New("invalid qr code")
+	}
+
+	data.PropertyID = target.Property.ID
+	data.RoomNo = strings.TrimSpace(data.RoomNo)
+	if target.Room != nil {
+		if data.RoomNo != "" && !strings.EqualFold(data.RoomNo, target.Room.RoomNumber) {
+			return data, errors.New("tenant_not_verified")
+		}
+		data.RoomNo = target.Room.RoomNumber
+	} else if data.RoomNo == "" {
+		return data, errors.New("invalid room: room number is required")
+	}
+
+	if _, err := verifyComplainant(data); err != nil {
+		return data, err
+	}
+	return data, nil
+}
+
+// QRPoster is a generated poster file
+type QRPoster struct {
+	Data        []byte
+	ContentType string
+	FileName    string
+}
+
+// GenerateQRPosters renders the complaint-form QR for a property. roomID 0 gives the property-wide poster,
+// allRooms adds one page per room (PDF only). format is "pdf" or "png"; PNG is the bare QR code.
+func GenerateQRPosters(propertyID, ownerID, roomID uint, allRooms bool, format string) (QRPoster, error) {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return QRPoster{}, err
+	}
+	if format != "pdf" && format != "png" {
+		return QRPoster{}, errors.New("invalid format: use pdf or png")
+	}
+	if allRooms && format == "png" {
+		return QRPoster{}, errors.New("invalid format: posters for all rooms come as one pdf")
+	}
+	property := loadProperty(propertyID)
+
+	// 1. Which posters
+	var rooms []models.Room
+	switch {
+	case allRooms:
+		config.DB.Where("property_id = ?", propertyID).Order("room_no ASC").Find(&rooms)
+		if len(rooms) == 0 {
+			return QRPoster{}, errors.New("room not found")
+		}
+	case roomID != 0:
+		var room models.Room
+		if err := config.DB.Where("id = ? AND property_id = ?", roomID, propertyID).First(&room).Error; err != nil {
+			return QRPoster{}, errors.New("room not found")
+		}
+		rooms = []models.Room{room}
+	}
+
+	suffix := "property"
+	if roomID != 0 && len(rooms) == 1 {
+		suffix = "room_" + rooms[0].RoomNumber
+	} else if allRooms {
+		suffix = "rooms"
+	}
+	fileName := fmt.Sprintf("complaint_qr_%d_%s.%s", propertyID, strings.NewReplacer("/", "-", " ", "-").Replace(suffix), format)
+
+	// 2. PNG: just the code, for owners who design their own poster
+	if format == "png" {
+		var id uint
+		if len(rooms) == 1 {
+			id = rooms[0].ID
+		}
+		png, err := utils.GenerateUPIQR(qrFormURL(qrToken(qrFormComplaint, property, id)), 1024)
+		if err != nil {
+			return QRPoster{}, err
+		}
+		return QRPoster{Data: png, ContentType: "image/png", FileName: fileName}, nil
+	}
+
+	// 3. PDF: one A4 page per poster
+	page := func(roomID uint, roomNo string) (utils.PosterPage, error) {
+		url := qrFormURL(qrToken(qrFormComplaint, property, roomID))
+		png, err := utils.GenerateUPIQR(url, 1024)
+		return utils.PosterPage{
+			Heading:    "Something not working?",
+			Subheading: "Scan to report a problem. Only registered tenants can submit.",
+			RoomNo:     roomNo,
+			URL:        url,
+			QR:         png,
+		}, err
+	}
+
+	var pages []utils.PosterPage
+	if len(rooms) == 0 {
+		p, err := page(0, "")
+		if err != nil {
+			return QRPoster{}, err
+		}
+		pages = append(pages, p)
+	}
+	for _, room := range rooms {
+		p, err := page(room.ID, room.RoomNumber)
+		if err != nil {
+			return QRPoster{}, err
+		}
+		pages = append(pages, p)
+	}
+
+	pdf, err := utils.GenerateQRPoster(propertyLetterhead(property), pages)
+	if err != nil {
+		return QRPoster{}, err
+	}
+	return QRPoster{Data: pdf, ContentType: "application/pdf", FileName: fileName}, nil
+}
+
+// RotateQRCodes voids every QR poster printed for the property, e.g. after one was photographed and shared
+func RotateQRCodes(propertyID, ownerID uint) error {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return err
+	}
+	return config.DB.Model(&models.Property{}).Where("id = ?", propertyID).
+		Update("qr_version", gorm.Expr("qr_version + 1")).Error
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"log"
+	"net/http"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"pg-manager-backend/storage"
+	"pg-manager-backend/utils"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var gstinPattern = regexp.MustCompile(`^[0-9]{2}[A-Z]{5}[0-9]{4}[A-Z][1-9A-Z]Z[0-9A-Z]$`)
+
+// Logos are printed small; anything bigger is almost certainly a camera photo
+const maxLogoBytes = 1 << 20
+
+// ReceiptVerification is what the public QR check reveals about a receipt
+type ReceiptVerification struct {
+	ReceiptNo    string    `json:"receipt_no"`
+	PropertyName string    `json:"property_name"`
+	TenantName   string    `json:"tenant_name"` // First name only
+	Amount       float64   `json:"amount"`
+	Method       string    `json:"method"`
+	Date         time.Time `json:"date"`
+	Status       string    `json:"status"`
+}
+
+// propertyLetterhead loads the branding printed on a property's receipts and invoices
+func propertyLetterhead(property models.Property) utils.Letterhead {
+	head := utils.Letterhead{
+		Name:    property.Name,
+		Address: property.Address,
+		Phone:   property.ContactPhone,
+		Email:   property.ContactEmail,
+		GSTIN:   property.GSTIN,
+	}
+	if property.LogoPath != "" {
+		if data, err := storage.Default().Get(property.LogoPath); err == nil {
+			head.Logo = data
+			head.LogoType = "PNG"
+			if http.DetectContentType(data) == "image/jpeg" {
+				head.LogoType = "JPG"
+			}
+		}
+	}
+	return head
+}
+
+// receiptSignature is an HMAC of the payment ID so receipt numbers cannot be guessed into the verify endpoint
+func receiptSignature(paymentID uint) string {
+	mac := hmac.New(sha256.New, []byte(config.App.JWTSecret))
+	mac.Write([]byte("receipt:" + strconv.FormatUint(uint64(paymentID), 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:12])
+}
+
+// downloadURL is a signed, expiring link to a stored file, for tenant messages
+func downloadURL(key string) string {
+	return storage.SignedURL(key, time.Duration(config.App.DownloadTTL)*time.Hour)
+}
+
+// receiptKey is where a payment's receipt PDF is stored
+func receiptKey(paymentID uint) string {
+	return "receipts/receipt_" + strconv.FormatUint(uint64(paymentID), 10) + ".pdf"
+}
+
+// renderReceipt generates the branded receipt PDF with the payment's invoice allocation breakdown
+// and stores it, returning its storage key
+func renderReceipt(payment models.Payment, tenantName string) (string, error) {
+	var property models.Property
+	config.DB.First(&property, payment.PropertyID)
+
+	roomNo := ""
+	var profile models.TenantProfile
+	if err := config.DB.Unscoped().Preload("Room").Where("user_id = ?", payment.TenantID).First(&profile).Error; err == nil {
+		roomNo = profile.Room.RoomNumber
+	}
+
+	againstNo := ""
+	if payment.ReversalOf != nil {
+		var original models.Payment
+		if err := config.DB.First(&original, *payment.ReversalOf).Error; err == nil {
+			againstNo = utils.ReceiptNumber(original)
+		}
+	}
+
+	pdf, err := utils.GenerateReceipt(utils.ReceiptData{
+		Payment:     payment,
+		TenantName:  tenantName,
+		RoomNo:      roomNo,
+		Letterhead:  propertyLetterhead(property),
+		Allocations: getPaymentAllocations(payment.ID),
+		AgainstNo:   againstNo,
+		VerifyURL: config.App.BaseURL + "/api/receipts/" + strconv.FormatUint(uint64(payment.ID), 10) +
+			"/verify?sig=" + receiptSignature(payment.ID),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	key := receiptKey(payment.ID)
+	if err := storage.Default().Put(key, pdf, "application/pdf"); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// VerifyReceipt confirms a scanned receipt is genuine and still stands (not reversed)
+func VerifyReceipt(paymentID uint, signature string) (ReceiptVerification, error) {
+	if !hmac.Equal([]byte(signature), []byte(receiptSignature(paymentID))) {
+		return ReceiptVerification{}, errors.New("invalid receipt signature")
+	}
+
+	var payment models.Payment
+	if err := config.DB.First(&payment, paymentID).Error; err != nil {
+		return ReceiptVerification{}, errors.New("receipt not found")
+	}
+
+	var property models.Property
+	config.DB.First(&property, payment.PropertyID)
+
+	var user models.User
+	config.DB.First(&user, payment.TenantID)
+
+	return ReceiptVerification{
+		ReceiptNo:    utils.ReceiptNumber(payment),
+		PropertyName: property.Name,
+		TenantName:   strings.SplitN(strings.TrimSpace(user.Name), " ", 2)[0],
+		Amount:       payment.Amount,
+		Method:       payment.Method,
+		Date:         payment.Date,
+		Status:       payment.Status,
+	}, nil
+}
+
+// UpdatePropertyBranding sets the address, contacts and GSTIN printed on receipts and invoices
+func UpdatePropertyBranding(propertyID, ownerID uint, address, phone, email, gstin string) error {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return err
+	}
+
+	gstin = strings.ToUpper(strings.TrimSpace(gstin))
+	if gstin != "" && !gstinPattern.MatchString(gstin) {
+		return errors.New("invalid GSTIN: expected 15 characters like 29ABCDE1234F1Z5")
+	}
+	email = strings.TrimSpace(email)
+	if email != "" && !utils.IsValidEmail(email) {
+		return errors.New("invalid contact email")
+	}
+	if phone = strings.TrimSpace(phone); phone != "" {
+		normalized, err := utils.NormalizePhone(phone)
+		if err != nil {
+			return errors.New("invalid contact phone")
+		}
+		phone = normalized
+	}
+
+	return config.DB.Model(&models.Property{}).Where("id = ?", propertyID).Updates(map[string]interface{}{
+		"address":       strings.TrimSpace(address),
+		"contact_phone": phone,
+		"contact_email": email,
+		"gstin":         gstin,
+	}).Error
+}
+
+// UploadPropertyLogo stores a PNG/JPEG logo for the property's letterhead
+func UploadPropertyLogo(propertyID, ownerID uint, data []byte) error {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return err
+	}
+	if len(data) > maxLogoBytes {
+		return errors.New("invalid logo: file must be under 1 MB")
+	}
+
+	ext := ""
+	switch http.DetectContentType(data) {
+	case "image/png":
+		ext = ".png"
+	case "image/jpeg":
+		ext = ".jpg"
+	default:
+		return errors.New("invalid logo: only PNG and JPEG are supported")
+	}
+
+	key := "logos/property_" + strconv.FormatUint(uint64(propertyID), 10) + ext
+	if err := storage.Default().Put(key, data, http.DetectContentType(data)); err != nil {
+		log.Printf("⚠️ Logo upload failed for property %d: %v", propertyID, err)
+		return errors.New("could not save logo")
+	}
+
+	return config.DB.Model(&models.Property{}).Where("id = ?", propertyID).Update("logo_path", key).Error
+}
@@ -0,0 +1,235 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"pg-manager-backend/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// ReversePayment cancels a mistaken entry by posting an equal and opposite payment row
+func ReversePayment(ownerID, paymentID uint, reason string) (models.Payment, float64, error) {
+	reason = strings.TrimSpace(reason)
+	if len(reason) < 5 {
+		return models.Payment{}, 0, errors.New("a reason of at least 5 characters is required")
+	}
+
+	var original models.Payment
+	if err := config.DB.First(&original, paymentID).Error; err != nil {
+		return models.Payment{}, 0, errors.New("payment not found")
+	}
+	if err := verifyPropertyOwner(original.PropertyID, ownerID); err != nil {
+		return models.Payment{}, 0, err
+	}
+	if original.Status == "reversed" {
+		return models.Payment{}, 0, errors.New("payment is already reversed")
+	}
+	if original.PaymentType == "Reversal" {
+		return models.Payment{}, 0, errors.New("a reversal cannot itself be reversed")
+	}
+
+	tx := config.DB.Begin()
+
+	// Flag the original first; only one of two concurrent reversals can win this update
+	now := time.Now()
+	flag := tx.Model(&models.Payment{}).Where("id = ? AND status = ?", original.ID, "completed").
+		Updates(map[string]interface{}{"status": "reversed", "reversed_at": &now})
+	if flag.Error != nil {
+		tx.Rollback()
+		return models.Payment{}, 0, flag.Error
+	}
+	if flag.RowsAffected != 1 {
+		tx.Rollback()
+		return models.Payment{}, 0, errors.New("payment is already reversed")
+	}
+	original.Status = "reversed"
+	original.ReversedAt = &now
+
+	var profile models.TenantProfile
+	if err := tx.First(&profile, "user_id = ?", original.TenantID).Error; err != nil {
+		tx.Rollback()
+		return models.Payment{}, 0, errors.New("tenant is no longer active; reverse before offboarding")
+	}
+
+	reversal := models.Payment{
+		Amount:      -original.Amount,
+		PaymentType: "Reversal",
+		Method:      original.Method,
+		ReversalOf:  &original.ID,
+		Reason:      reason,
+		RecordedBy:  ownerID,
+	}
+
+	var newBalance float64
+	if original.PaymentType == "Deposit-Refund" {
+		// Deposit refunds never touched the balance, so neither does undoing one
+		newBalance = profile.Balance
+		reversal.BalanceAfter = &newBalance
+		reversal.TenantID = profile.UserID
+		reversal.PropertyID = profile.PropertyID
+		reversal.Date = time.Now()
+		receiptNo, err := nextDocumentNumber(tx, reversal.PropertyID, "receipt", reversal.Date)
+		if err != nil {
+			tx.Rollback()
+			return models.Payment{}, 0, err
+		}
+		reversal.ReceiptNo = receiptNo
+		if err := tx.Create(&reversal).Error; err != nil {
+			tx.Rollback()
+			return models.Payment{}, 0, err
+		}
+	} else {
+		var err error
+		newBalance, err = applyPayment(tx, &profile, &reversal, nil)
+		if err != nil {
+			tx.Rollback()
+			return models.Payment{}, 0, err
+		}
+	}
+
+	// Whatever invoices this payment settled are owed again
+	if err := releaseAllocations(tx, original, reversal); err != nil {
+		tx.Rollback()
+		return models.Payment{}, 0, err
+	}
+
+	// A wrongly matched bank credit goes back to the review queue
+	if err := tx.Model(&models.BankStatementLine{}).Where("payment_id = ?", original.ID).
+		Updates(map[string]interface{}{"status": "unmatched", "payment_id": nil}).Error; err != nil {
+		tx.Rollback()
+		return models.Payment{}, 0, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return models.Payment{}, 0, err
+	}
+
+	sendAdjustmentNotice(original, reversal, profile, newBalance)
+
+	return reversal, newBalance, nil
+}
+
+// RefundTenant pays money back to a tenant. Overpayment refunds use up the tenant's credit;
+// deposit refunds are tracked against the held deposit and leave the rent balance alone.
+func RefundTenant(ownerID, tenantID uint, amount float64, method, kind, reason string, paymentID *uint) (models.Payment, float64, error) {
+	reason = strings.TrimSpace(reason)
+	if len(reason) < 5 {
+		return models.Payment{}, 0, errors.New("a reason of at least 5 characters is required")
+	}
+	if amount <= 0 {
+		return models.Payment{}, 0, errors.New("refund amount must be greater than zero")
+	}
+
+	var profile models.TenantProfile
+	if err := config.DB.First(&profile, "user_id = ?", tenantID).Error; err != nil {
+		return models.Payment{}, 0, errors.New("tenant profile not found")
+	}
+	if err := verifyPropertyOwner(profile.PropertyID, ownerID); err != nil {
+		return models.Payment{}, 0, err
+	}
+
+	if paymentID != nil {
+		var original models.Payment
+		if err := config.DB.First(&original, "id = ? AND tenant_id = ?", *paymentID, tenantID).Error; err != nil {
+			return models.Payment{}, 0, errors.New("payment not found")
+		}
+	}
+
+	refund := models.Payment{
+		Amount:     -amount,
+		Method:     method,
+		ReversalOf: paymentID,
+		Reason:     reason,
+		RecordedBy: ownerID,
+	}
+
+	tx := config.DB.Begin()
+
+	// Lock the tenant so concurrent refunds see each other's deposit and credit usage
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&profile, profile.ID).Error; err != nil {
+		tx.Rollback()
+		return models.Payment{}, 0, err
+	}
+
+	var newBalance float64
+	switch kind {
+	case "deposit":
+		var refunded float64
+		tx.Model(&models.Payment{}).
+			Where("tenant_id = ? AND payment_type = ? AND status = ?", tenantID, "Deposit-Refund", "completed").
+			Select("COALESCE(SUM(-amount), 0)").Scan(&refunded)
+		if refunded+amount > profile.Deposit+0.01 {
+			tx.Rollback()
+			return models.Payment{}, 0, fmt.Errorf("refund exceeds remaining deposit of ₹%.2f", profile.Deposit-refunded)
+		}
+
+		refund.PaymentType = "Deposit-Refund"
+		if err := recordPayment(tx, &profile, &refund, profile.Balance, nil); err != nil {
+			tx.Rollback()
+			return models.Payment{}, 0, err
+		}
+		newBalance = profile.Balance
+
+	case "overpayment", "":
+		// A negative balance is money the tenant has paid in advance
+		if profile.Balance+amount > 0.01 {
+			tx.Rollback()
+			return models.Payment{}, 0, fmt.Errorf("refund exceeds tenant credit of ₹%.2f", -profile.Balance)
+		}
+
+		refund.PaymentType = "Refund"
+		var err error
+		newBalance, err = applyPayment(tx, &profile, &refund, nil)
+		if err != nil {
+			tx.Rollback()
+			return models.Payment{}, 0, err
+		}
+		if err := consumeCredit(tx, tenantID, amount, paymentID); err != nil {
+			tx.Rollback()
+			return models.Payment{}, 0, err
+		}
+
+	default:
+		tx.Rollback()
+		return models.Payment{}, 0, errors.New("refund kind must be 'overpayment' or 'deposit'")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return models.Payment{}, 0, err
+	}
+
+	sendAdjustmentNotice(models.Payment{}, refund, profile, newBalance)
+
+	return refund, newBalance, nil
+}
+
+// sendAdjustmentNotice re-stamps the original receipt (if any), issues the credit note and informs the tenant
+func sendAdjustmentNotice(original, adjustment models.Payment, profile models.TenantProfile, newBalance float64) {
+	if original.ID != 0 {
+		if _, err := renderReceipt(original, profile.Name); err != nil {
+			log.Printf("⚠️ Could not annotate receipt %s: %v", utils.ReceiptNumber(original), err)
+		}
+	}
+
+	key, err := renderReceipt(adjustment, profile.Name)
+	if err != nil {
+		log.Printf("⚠️ Receipt Generation Failed: %v", err)
+		return
+	}
+
+	receiptURL := downloadURL(key)
+	notifyTenant(profile, "account_adjustment", map[string]interface{}{
+		"Kind":       strings.ToLower(adjustment.PaymentType),
+		"Amount":     math.Abs(adjustment.Amount),
+		"Reason":     adjustment.Reason,
+		"Balance":    newBalance,
+		"ReceiptURL": receiptURL,
+	})
+}
@@ -0,0 +1,374 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"pg-manager-backend/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OverdueTenant is one row of the owner's overdue dashboard
+type OverdueTenant struct {
+	TenantID       uint       `json:"tenant_id"`
+	TenantName     string     `json:"tenant_name"`
+	RoomNo         string     `json:"room_no"`
+	PhoneNumber    string     `json:"phone_number"`
+	AmountOverdue  float64    `json:"amount_overdue"`
+	OldestDueDate  time.Time  `json:"oldest_due_date"`
+	DaysOverdue    int        `json:"days_overdue"`
+	LastReminderAt *time.Time `json:"last_reminder_at"`
+}
+
+// OverdueBucket groups overdue tenants by how late their oldest unpaid invoice is
+type OverdueBucket struct {
+	Label   string          `json:"label"`
+	Total   float64         `json:"total"`
+	Tenants []OverdueTenant `json:"tenants"`
+}
+
+// overdueBuckets are inclusive day ranges; -1 means no upper bound
+var overdueBuckets = []struct {
+	Label    string
+	From, To int
+}{
+	{"1-7 days", 1, 7},
+	{"8-15 days", 8, 15},
+	{"16-30 days", 16, 30},
+	{"30+ days", 31, -1},
+}
+
+// parseReminderOffsets turns "-3,0,3,7" into a sorted list of day offsets
+func parseReminderOffsets(raw string) ([]int, error) {
+	var offsets []int
+	seen := map[int]bool{}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < -30 || n > 90 {
+			return nil, fmt.Errorf("invalid reminder offset: %s", part)
+		}
+		if !seen[n] {
+			seen[n] = true
+			offsets = append(offsets, n)
+		}
+	}
+	sort.Ints(offsets)
+	return offsets, nil
+}
+
+// parseClock reads "HH:MM" as minutes since midnight
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// inQuietHours handles windows that wrap past midnight (e.g. 21:00–08:00)
+func inQuietHours(property models.Property, now time.Time) bool {
+	start, err1 := parseClock(property.QuietHoursStart)
+	end, err2 := parseClock(property.QuietHoursEnd)
+	if err1 != nil || err2 != nil || start == end {
+		return false
+	}
+	minute := now.Hour()*60 + now.Minute()
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+func daysBetween(from, to time.Time) int {
+	return int(dateOnly(to).Sub(dateOnly(from)).Hours() / 24)
+}
+
+// UpdateReminderSettings changes a property's reminder schedule and quiet hours
+func UpdateReminderSettings(propertyID, ownerID uint, offsets []int, quietStart, quietEnd string) error {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return err
+	}
+
+	parts := make([]string, len(offsets))
+	for i, n := range offsets {
+		parts[i] = strconv.Itoa(n)
+	}
+	normalized, err := parseReminderOffsets(strings.Join(parts, ","))
+	if err != nil {
+		return err
+	}
+	if _, err := parseClock(quietStart); err != nil {
+		return err
+	}
+	if _, err := parseClock(quietEnd); err != nil {
+		return err
+	}
+
+	parts = parts[:0]
+	for _, n := range normalized {
+		parts = append(parts, strconv.Itoa(n))
+	}
+
+	return config.DB.Model(&models.Property{}).Where("id = ?", propertyID).Updates(map[string]interface{}{
+		"reminder_offsets":  strings.Join(parts, ","),
+		"quiet_hours_start": quietStart,
+		"quiet_hours_end":   quietEnd,
+	}).Error
+}
+
+// recordReminder marks a due date/offset as sent; false means it was already sent. Delete the
+// returned row if the message then cannot be queued, so the next run tries again.
+func recordReminder(profile models.TenantProfile, invoiceID *uint, dueDate time.Time, offset int) (models.RentReminder, bool) {
+	reminder := models.RentReminder{
+		PropertyID: profile.PropertyID,
+		TenantID:   profile.UserID,
+		InvoiceID:  invoiceID,
+		DueDate:    dateOnly(dueDate),
+		OffsetDays: offset,
+		SentAt:     time.Now(),
+	}
+	// Once per invoice and offset; before the invoice exists, once per tenant, due date and offset
+	query := config.DB.Where("invoice_id IS NULL AND tenant_id = ? AND due_date = ? AND offset_days = ?", profile.UserID, reminder.DueDate, offset)
+	if invoiceID != nil {
+		query = config.DB.Where("invoice_id = ? AND offset_days = ?", *invoiceID, offset)
+	}
+	result := query.FirstOrCreate(&reminder)
+	return reminder, result.Error == nil && result.RowsAffected == 1
+}
+
+// ProcessRentReminders sends the scheduled reminders that are due now. It is safe to run hourly:
+// each (tenant, due date, offset) is sent once, properties in quiet hours are skipped until the next run,
+// and when several offsets were missed only the latest one goes out. A non-zero ownerID limits the run
+// to that owner's properties.
+func ProcessRentReminders(ownerID uint) error {
+	var properties []models.Property
+	if err := ownerScope(config.DB, "owner_id", ownerID).Find(&properties).Error; err != nil {
+		log.Printf("Error fetching properties for reminders: %v", err)
+		return err
+	}
+
+	now := time.Now()
+	sent := 0
+	for _, property := range properties {
+		if inQuietHours(property, now) {
+			continue
+		}
+		offsets, err := parseReminderOffsets(property.ReminderOffsets)
+		if err != nil || len(offsets) == 0 {
+			continue
+		}
+		sent += sendUpcomingReminders(property, offsets, now)
+		sent += sendOverdueReminders(property, offsets, now)
+	}
+
+	log.Printf("⏰ Rent reminders: %d sent", sent)
+	return nil
+}
+
+// latestOffset picks the largest offset in [min, days] so a missed run never causes a burst of reminders
+func latestOffset(offsets []int, days, min int) (int, bool) {
+	for i := len(offsets) - 1; i >= 0; i-- {
+		if offsets[i] <= days && offsets[i] >= min {
+			return offsets[i], true
+		}
+	}
+	return 0, false
+}
+
+// sendUpcomingReminders covers the offsets before the due date, when the month's invoice does not exist yet.
+// Offset 0 is the rent_due message ProcessDailyBilling sends with the invoice.
+func sendUpcomingReminders(property models.Property, offsets []int, now time.Time) int {
+	if offsets[0] >= 0 {
+		return 0
+	}
+	horizon := dateOnly(now).AddDate(0, 0, 1-offsets[0])
+
+	var tenants []models.TenantProfile
+	config.DB.Where("property_id = ? AND status = ? AND next_billing_date >= ? AND next_billing_date < ?",
+		property.ID, "active", dateOnly(now).AddDate(0, 0, 1), horizon).Find(&tenants)
+
+	sent := 0
+	for _, tenant := range tenants {
+		daysLeft := daysBetween(now, tenant.NextBillingDate)
+		offset, ok := latestOffset(offsets, -daysLeft, offsets[0])
+		if !ok || offset >= 0 {
+			continue
+		}
+		// Advance credit already covers the coming rent
+		if -tenant.Balance >= tenant.MonthlyRent {
+			continue
+		}
+		reminder, ok := recordReminder(tenant, nil, tenant.NextBillingDate, offset)
+		if !ok {
+			continue
+		}
+
+		payLink := ""
+		if due := tenant.Balance + tenant.MonthlyRent; due > 0 {
+			if link, err := utils.GenerateRazorpayLink(tenant.UserID, tenant.MailID, due, "Monthly Rent"); err == nil {
+				payLink = link
+			}
+		}
+
+		err := notifyTenant(tenant, "rent_reminder", map[string]interface{}{
+			"Rent":     tenant.MonthlyRent,
+			"Balance":  tenant.Balance,
+			"DaysLeft": daysLeft,
+			"DueDate":  tenant.NextBillingDate.Format("02 Jan 2006"),
+			"PayLink":  payLink,
+		})
+		if err != nil {
+			config.DB.Delete(&reminder)
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+// sendOverdueReminders covers the offsets after the due date; paid invoices drop out of the query
+func sendOverdueReminders(property models.Property, offsets []int, now time.Time) int {
+	if offsets[len(offsets)-1] <= 0 {
+		return 0
+	}
+
+	var invoices []models.Invoice
+	config.DB.Where("property_id = ? AND status IN ? AND due_date < ?", property.ID, []string{"open", "partial"}, dateOnly(now)).
+		Order("due_date ASC").Find(&invoices)
+
+	sent := 0
+	for _, invoice := range invoices {
+		daysOverdue := daysBetween(invoice.DueDate, now)
+		offset, ok := latestOffset(offsets, daysOverdue, 1)
+		if !ok {
+			continue
+		}
+
+		var tenant models.TenantProfile
+		if err := config.DB.Where("user_id = ? AND status = ?", invoice.TenantID, "active").First(&tenant).Error; err != nil {
+			continue
+		}
+		invoiceID := invoice.ID
+		reminder, ok := recordReminder(tenant, &invoiceID, invoice.DueDate, offset)
+		if !ok {
+			continue
+		}
+
+		amountDue := invoice.Amount - invoice.AmountPaid
+		payLink, err := utils.GenerateRazorpayLink(tenant.UserID, tenant.MailID, amountDue, "Overdue "+invoice.Reference)
+		if err != nil {
+			payLink = "[Link Unavailable]"
+		}
+		_, upiLink := invoicePaymentDetails(invoice, tenant.Name)
+
+		err = notifyTenant(tenant, "rent_overdue", map[string]interface{}{
+			"AmountDue":   amountDue,
+			"Reference":   invoice.Reference,
+			"DaysOverdue": daysOverdue,
+			"PayLink":     payLink,
+			"UPILink":     upiLink,
+		})
+		if err != nil {
+			config.DB.Delete(&reminder)
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+// GetOverdueBuckets lists tenants with unpaid invoices past their due date, grouped by age
+func GetOverdueBuckets(propertyID, ownerID uint) ([]OverdueBucket, error) {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		TenantID      uint
+		TenantName    string
+		RoomNo        string
+		PhoneNumber   string
+		AmountOverdue float64
+		OldestDueDate time.Time
+	}
+	err := config.DB.Table("invoices").
+		Select("invoices.tenant_id, tenant_profiles.name AS tenant_name, rooms.room_no, tenant_profiles.phone_number, "+
+			"SUM(invoices.amount - invoices.amount_paid) AS amount_overdue, MIN(invoices.due_date) AS oldest_due_date").
+		Joins("JOIN tenant_profiles ON tenant_profiles.user_id = invoices.tenant_id AND tenant_profiles.deleted_at IS NULL AND tenant_profiles.status = ?", "active").
+		Joins("LEFT JOIN rooms ON rooms.id = tenant_profiles.room_id").
+		Where("invoices.property_id = ? AND invoices.status IN ? AND invoices.due_date < ?",
+			propertyID, []string{"open", "partial"}, dateOnly(time.Now())).
+		Group("invoices.tenant_id, tenant_profiles.name, rooms.room_no, tenant_profiles.phone_number").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, errors.New("could not load overdue invoices")
+	}
+
+	// Last reminder per tenant, in one query
+	tenantIDs := make([]uint, len(rows))
+	for i, row := range rows {
+		tenantIDs[i] = row.TenantID
+	}
+	var reminders []struct {
+		TenantID uint
+		SentAt   time.Time
+	}
+	if len(tenantIDs) > 0 {
+		config.DB.Model(&models.RentReminder{}).Select("tenant_id, MAX(sent_at) AS sent_at").
+			Where("tenant_id IN ?", tenantIDs).Group("tenant_id").Scan(&reminders)
+	}
+	lastReminder := map[uint]time.Time{}
+	for _, r := range reminders {
+		lastReminder[r.TenantID] = r.SentAt
+	}
+
+	buckets := make([]OverdueBucket, len(overdueBuckets))
+	for i, b := range overdueBuckets {
+		buckets[i] = OverdueBucket{Label: b.Label, Tenants: []OverdueTenant{}}
+	}
+
+	for _, row := range rows {
+		entry := OverdueTenant{
+			TenantID:      row.TenantID,
+			TenantName:    row.TenantName,
+			RoomNo:        row.RoomNo,
+			PhoneNumber:   row.PhoneNumber,
+			AmountOverdue: roundPaise(row.AmountOverdue),
+			OldestDueDate: row.OldestDueDate,
+			DaysOverdue:   daysBetween(row.OldestDueDate, time.Now()),
+		}
+
+		if sentAt, ok := lastReminder[row.TenantID]; ok {
+			entry.LastReminderAt = &sentAt
+		}
+
+		for i, b := range overdueBuckets {
+			if entry.DaysOverdue >= b.From && (b.To == -1 || entry.DaysOverdue <= b.To) {
+				buckets[i].Tenants = append(buckets[i].Tenants, entry)
+				buckets[i].Total = roundPaise(buckets[i].Total + entry.AmountOverdue)
+				break
+			}
+		}
+	}
+
+	for i := range buckets {
+		sort.Slice(buckets[i].Tenants, func(a, b int) bool {
+			return buckets[i].Tenants[a].DaysOverdue > buckets[i].Tenants[b].DaysOverdue
+		})
+	}
+	return buckets, nil
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"fmt"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"testing"
+	"time"
+)
+
+func TestUnqueuedReminderIsRetried(t *testing.T) {
+	requireDB(t)
+	now := time.Now()
+	property, tenant := createTestTenant(t, models.TenantProfile{Name: "Ravi", MonthlyRent: 8000})
+	invoice := models.Invoice{PropertyID: property.ID, TenantID: tenant.UserID, Amount: 8000, Status: "open",
+		DueDate: dateOnly(now).AddDate(0, 0, -3), Reference: fmt.Sprintf("T%09d", now.UnixNano()%1e9)}
+	if err := config.DB.Create(&invoice).Error; err != nil {
+		t.Fatalf("create invoice: %v", err)
+	}
+
+	// Opted out everywhere, so nothing can be queued
+	config.DB.Model(&tenant).Updates(map[string]interface{}{"whatsapp_opt_out_at": now, "sms_opt_out_at": now})
+	if sent := sendOverdueReminders(property, []int{-3, 0, 3}, now); sent != 0 {
+		t.Fatalf("sent %d reminders to a tenant who opted out", sent)
+	}
+	var recorded int64
+	config.DB.Model(&models.RentReminder{}).Where("invoice_id = ?", invoice.ID).Count(&recorded)
+	if recorded != 0 {
+		t.Fatal("reminder marked as sent although nothing was queued")
+	}
+
+	// Once a channel is back, the next run sends it
+	config.DB.Model(&tenant).Update("sms_opt_out_at", nil)
+	if sent := sendOverdueReminders(property, []int{-3, 0, 3}, now); sent != 1 {
+		t.Errorf("sent %d reminders after opting back in, want 1", sent)
+	}
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"strings"
+	"time"
+)
+
+// PropertySummary is the money and maintenance position of one property over a period
+type PropertySummary struct {
+	PropertyID     uint    `json:"property_id"`
+	PropertyName   string  `json:"property_name"`
+	Collected      float64 `json:"collected"` // Net of reversals and refunds
+	Expenditure    float64 `json:"expenditure"`
+	OutstandingDue float64 `json:"outstanding_due"` // Current positive balances of active tenants
+	OpenComplaints int64   `json:"open_complaints"`
+}
+
+// summarizeProperty totals payments and expenditure in [from, to) plus today's dues and open complaints
+func summarizeProperty(property models.Property, from, to time.Time) PropertySummary {
+	summary := PropertySummary{PropertyID: property.ID, PropertyName: property.Name}
+
+	config.DB.Model(&models.Payment{}).
+		Where("property_id = ? AND date >= ? AND date < ?", property.ID, from, to).
+		Select("COALESCE(SUM(amount), 0)").Scan(&summary.Collected)
+
+	config.DB.Model(&models.Expenditure{}).
+		Where("property_id = ? AND date >= ? AND date < ?", property.ID, from, to).
+		Select("COALESCE(SUM(amount), 0)").Scan(&summary.Expenditure)
+
+	config.DB.Model(&models.TenantProfile{}).
+		Where("property_id = ? AND status = ? AND balance > 0", property.ID, "active").
+		Select("COALESCE(SUM(balance), 0)").Scan(&summary.OutstandingDue)
+
+	config.DB.Model(&models.Complaint{}).
+		Where("property_id = ? AND status IN ?", property.ID, openComplaintStatuses).
+		Count(&summary.OpenComplaints)
+
+	summary.Collected = roundPaise(summary.Collected)
+	summary.Expenditure = roundPaise(summary.Expenditure)
+	summary.OutstandingDue = roundPaise(summary.OutstandingDue)
+	return summary
+}
+
+// SendOwnerMonthlyReports sends each owner (or only ownerID) last month's collections, expenditure, dues and open complaints
+func SendOwnerMonthlyReports(ownerID uint) error {
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	from := to.AddDate(0, -1, 0)
+
+	ownerIDs, err := jobOwnerIDs(ownerID)
+	if err != nil {
+		return err
+	}
+
+	for _, ownerID := range ownerIDs {
+		var owner models.User
+		if err := config.DB.First(&owner, ownerID).Error; err != nil {
+			continue
+		}
+		properties, _ := GetAllProperties(ownerID)
+
+		var body strings.Builder
+		body.WriteString(fmt.Sprintf("📊 Monthly Report — %s\n", from.Format("January 2006")))
+		for _, property := range properties {
+			s := summarizeProperty(property, from, to)
+			body.WriteString(fmt.Sprintf("\n🏠 %s\nCollected: ₹%.2f\nExpenditure: ₹%.2f\nNet: ₹%.2f\nOutstanding Dues: ₹%.2f\nOpen Complaints: %d\n",
+				s.PropertyName, s.Collected, s.Expenditure, roundPaise(s.Collected-s.Expenditure), s.OutstandingDue, s.OpenComplaints))
+		}
+
+		notifyOwner(owner, 0, "owner_monthly_report", "Monthly Report — "+from.Format("January 2006"), body.String())
+	}
+
+	log.Printf("📊 Monthly reports queued for %d owners", len(ownerIDs))
+	return nil
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"log"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"pg-manager-backend/storage"
+	"pg-manager-backend/utils"
+	"sort"
+	"strings"
+	"time"
+)
+
+// RegeneratedReceipt is returned after a receipt is rebuilt on demand
+type RegeneratedReceipt struct {
+	PaymentID uint   `json:"payment_id"`
+	ReceiptNo string `json:"receipt_no"`
+	URL       string `json:"url"`
+}
+
+// RegenerateReceipt rebuilds a payment's receipt PDF, e.g. after generation failed or branding changed
+func RegenerateReceipt(paymentID, ownerID uint) (RegeneratedReceipt, error) {
+	var payment models.Payment
+	if err := config.DB.First(&payment, paymentID).Error; err != nil {
+		return RegeneratedReceipt{}, errors.New("payment not found")
+	}
+	if err := verifyPropertyOwner(payment.PropertyID, ownerID); err != nil {
+		return RegeneratedReceipt{}, err
+	}
+
+	key, err := renderReceipt(payment, paymentTenantName(payment))
+	if err != nil {
+		log.Printf("⚠️ Receipt regeneration failed for payment #%d: %v", payment.ID, err)
+		return RegeneratedReceipt{}, errors.New("could not generate receipt")
+	}
+
+	return RegeneratedReceipt{PaymentID: payment.ID, ReceiptNo: utils.ReceiptNumber(payment), URL: downloadURL(key)}, nil
+}
+
+// paymentTenantName finds the payer's name even after they have been offboarded
+func paymentTenantName(payment models.Payment) string {
+	var profile models.TenantProfile
+	if err := config.DB.Unscoped().Where("user_id = ?", payment.TenantID).First(&profile).Error; err == nil {
+		return profile.Name
+	}
+	var user models.User
+	config.DB.Unscoped().First(&user, payment.TenantID)
+	return user.Name
+}
+
+// balanceEffect is how much a payment row moved the tenant's balance; deposit refunds never did
+func balanceEffect(payment models.Payment, depositRefunds map[uint]bool) float64 {
+	if payment.PaymentType == "Deposit-Refund" {
+		return 0
+	}
+	if payment.ReversalOf != nil && depositRefunds[*payment.ReversalOf] {
+		return 0
+	}
+	return payment.Amount
+}
+
+// GenerateTenantStatement renders every charge and payment between from and to (inclusive) with a running
+// balance. The opening balance is worked back from today's balance, so the statement always agrees with it.
+func GenerateTenantStatement(tenantID, ownerID uint, from, to time.Time) ([]byte, error) {
+	var profile models.TenantProfile
+	if err := config.DB.Unscoped().Preload("Room").Where("user_id = ?", tenantID).First(&profile).Error; err != nil {
+		return nil, errors.New("tenant profile not found")
+	}
+	if err := verifyPropertyOwner(profile.PropertyID, ownerID); err != nil {
+		return nil, err
+	}
+
+	from = dateOnly(from)
+	end := dateOnly(to).AddDate(0, 0, 1)
+	if !end.After(from) {
+		return nil, errors.New("invalid period: from must be on or before to")
+	}
+	if end.Sub(from) > 3*366*24*time.Hour {
+		return nil, errors.New("invalid period: at most three years per statement")
+	}
+
+	// 1. Everything from the start of the period onwards; later activity is needed to work back the balance
+	var invoices []models.Invoice
+	config.DB.Where("tenant_id = ? AND created_at >= ?", tenantID, from).Order("created_at ASC").Find(&invoices)
+
+	var payments []models.Payment
+	config.DB.Where("tenant_id = ? AND date >= ?", tenantID, from).Order("date ASC").Find(&payments)
+
+	depositRefunds := map[uint]bool{}
+	var refundIDs []uint
+	config.DB.Model(&models.Payment{}).Where("tenant_id = ? AND payment_type = ?", tenantID, "Deposit-Refund").Pluck("id", &refundIDs)
+	for _, id := range refundIDs {
+		depositRefunds[id] = true
+	}
+
+	// 2. Closing = today's balance minus what happened after the period; opening = closing minus the period
+	data := utils.StatementData{
+		Letterhead: propertyLetterhead(loadProperty(profile.PropertyID)),
+		TenantName: profile.Name,
+		RoomNo:     profile.Room.RoomNumber,
+		From:       from,
+		To:         dateOnly(to),
+	}
+
+	closing := profile.Balance
+	for _, inv := range invoices {
+		if !inv.CreatedAt.Before(end) {
+			closing -= inv.Amount
+		}
+	}
+	for _, p := range payments {
+		if !p.Date.Before(end) {
+			closing += balanceEffect(p, depositRefunds)
+		}
+	}
+
+	var lines []utils.StatementLine
+	for _, inv := range invoices {
+		if inv.CreatedAt.Before(end) {
+			number := inv.Number
+			if number == "" {
+				number = inv.Reference
+			}
+			lines = append(lines, utils.StatementLine{Date: inv.CreatedAt, Reference: number, Description: inv.Description, Charge: inv.Amount})
+			data.TotalCharges += inv.Amount
+		}
+	}
+	for _, p := range payments {
+		if !p.Date.Before(end) {
+			continue
+		}
+		description := p.Method + " " + strings.ToLower(p.PaymentType)
+		if p.Status == "reversed" {
+			description += " (reversed)"
+		}
+		effect := balanceEffect(p, depositRefunds)
+		if effect == 0 && p.Amount != 0 {
+			description += " - deposit, not in balance"
+		}
+		lines = append(lines, utils.StatementLine{Date: p.Date, Reference: utils.ReceiptNumber(p), Description: description, Payment: effect})
+		data.TotalPayments += effect
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].Date.Before(lines[j].Date) })
+
+	data.ClosingBalance = roundPaise(closing)
+	data.OpeningBalance = roundPaise(closing - data.TotalCharges + data.TotalPayments)
+	data.TotalCharges = roundPaise(data.TotalCharges)
+	data.TotalPayments = roundPaise(data.TotalPayments)
+
+	running := data.OpeningBalance
+	for i := range lines {
+		running = roundPaise(running + lines[i].Charge - lines[i].Payment)
+		lines[i].Balance = running
+	}
+	data.Lines = lines
+
+	return utils.GenerateStatement(data)
+}
+
+func loadProperty(propertyID uint) models.Property {
+	var property models.Property
+	config.DB.First(&property, propertyID)
+	return property
+}
+
+// ExportPropertyReceipts zips every receipt of a property for one month ("2025-01"),
+// generating any PDF that is missing or was cleaned up.
+func ExportPropertyReceipts(propertyID, ownerID uint, month string) ([]byte, error) {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return nil, err
+	}
+	start, err := time.ParseInLocation("2006-01", month, time.Local)
+	if err != nil {
+		return nil, errors.New("invalid month: expected YYYY-MM")
+	}
+
+	var payments []models.Payment
+	config.DB.Where("property_id = ? AND date >= ? AND date < ?", propertyID, start, start.AddDate(0, 1, 0)).
+		Order("date ASC, id ASC").Find(&payments)
+	if len(payments) == 0 {
+		return nil, errors.New("no payments in this month")
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	store := storage.Default()
+	var failed []string
+
+	for _, payment := range payments {
+		key := receiptKey(payment.ID)
+		pdf, err := store.Get(key)
+		if errors.Is(err, storage.ErrNotFound) {
+			if _, err = renderReceipt(payment, paymentTenantName(payment)); err == nil {
+				pdf, err = store.Get(key)
+			}
+		}
+		if err != nil {
+			log.Printf("⚠️ Export skipped receipt for payment #%d: %v", payment.ID, err)
+			failed = append(failed, utils.ReceiptNumber(payment))
+			continue
+		}
+
+		// "SRI/24-25/000123" → "SRI_24-25_000123.pdf"
+		name := strings.NewReplacer("/", "_", "#", "").Replace(utils.ReceiptNumber(payment)) + ".pdf"
+		w, err := archive.Create(name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(pdf); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(failed) > 0 {
+		w, err := archive.Create("MISSING.txt")
+		if err == nil {
+			fmt.Fprintf(w, "These receipts could not be generated:\n%s\n", strings.Join(failed, "\n"))
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -29,6 +29,11 @@ func generateOTP() string {
 
 // OnboardTenant handles initial registration and OTP dispatch
 func OnboardTenant(input models.TenantProfile) (uint, error) {
+	// 0. CONTACT DETAILS: E.164 phone, valid email and a channel we can actually use
+	if err := validateContactDetails(&input); err != nil {
+		return 0, err
+	}
+
 	tx := config.DB.Begin()
 
 	// 1. ROOM CAPACITY CHECK
@@ -76,12 +81,9 @@ func OnboardTenant(input models.TenantProfile) (uint, error) {
 		return 0, err
 	}
 
-	// 4. TERMINAL LOGGING
-	log.Printf("------------------------------------------------")
-	log.Printf("ADMISSION KYC COMPLETE - OTP GENERATED")
-	log.Printf("Tenant: %s | Phone: %s", input.Name, input.PhoneNumber)
-	log.Printf("OTP Code: %s", otpCode)
-	log.Printf("------------------------------------------------")
+	// 4. SEND OTP
+	log.Printf("ADMISSION KYC COMPLETE - OTP GENERATED for %s", input.Name)
+	notifyTenant(input, "otp", map[string]interface{}{"OTP": otpCode})
 
 	return newUser.ID, nil
 }
@@ -108,7 +110,19 @@ func VerifyTenantOTP(tenantID uint, inputOTP string) error {
 		"balance":     initialDue,
 	}
 
-	if err := config.DB.Model(&profile).Updates(updates).Error; err != nil {
+	tx := config.DB.Begin()
+	if err := tx.Model(&profile).Updates(updates).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	invoice, err := createInvoice(tx, profile, initialDue, "Initial Rent + Deposit", time.Now())
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit().Error; err != nil {
 		return err
 	}
 
@@ -119,17 +133,14 @@ func VerifyTenantOTP(tenantID uint, inputOTP string) error {
 		paymentLink = "[Link Error - Contact Admin]"
 	}
 
-	// TERMINAL LOGGING
-	log.Printf("\n--- WHATSAPP SIMULATION (OTP VERIFIED) ---")
-	log.Printf("To: %s (%s)", profile.Name, profile.PhoneNumber)
-	log.Printf("Message: ✅ Admission Confirmed! Your initial total (Rent+Deposit) is ₹%.2f. Pay here: %s", initialDue, paymentLink)
-	log.Printf("------------------------------------------\n")
-
-	// KEEPING THIS COMMENTED AS REQUESTED
-	/*
-	   welcomeMsg := fmt.Sprintf("✅ *Admission Confirmed!*\n🔗 Pay here: %s", paymentLink)
-	   utils.SendWhatsAppMessage(profile.PhoneNumber, welcomeMsg)
-	*/
+	invoiceURL, upiLink := invoicePaymentDetails(invoice, profile.Name)
+	notifyTenant(profile, "admission_confirmed", map[string]interface{}{
+		"AmountDue":  initialDue,
+		"PayLink":    paymentLink,
+		"Reference":  invoice.Reference,
+		"UPILink":    upiLink,
+		"InvoiceURL": invoiceURL,
+	})
 
 	return nil
 }
@@ -190,7 +201,7 @@ func OffboardTenant(tenantID string) error {
 	}
 
 	// Use a transaction to ensure either everything happens or nothing happens
-	return config.DB.Transaction(func(tx *gorm.DB) error {
+	err := config.DB.Transaction(func(tx *gorm.DB) error {
 
 		// 1. MOVE DATA TO BACKUP TABLE
 		archive := models.ArchivedTenant{
@@ -227,6 +238,15 @@ func OffboardTenant(tenantID string) error {
 
 		return nil // Transaction will commit here
 	})
+	if err != nil {
+		return err
+	}
+
+	var room models.Room
+	config.DB.First(&room, profile.RoomID)
+	alertOwner(profile.PropertyID, AlertRoomVacated, "Bed Vacated",
+		fmt.Sprintf("🚪 %s has checked out of Room %s. The bed is now free.", profile.Name, room.RoomNumber))
+	return nil
 }
 
 // GetArchivedTenants retrieves all records from the backup table
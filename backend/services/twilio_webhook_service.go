@@ -0,0 +1,135 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"pg-manager-backend/notifier"
+	"pg-manager-backend/utils"
+	"strings"
+	"time"
+)
+
+// deliveryRank orders Twilio statuses so late or out-of-order callbacks never move a message backwards
+var deliveryRank = map[string]int{
+	"accepted":    1,
+	"queued":      1,
+	"sending":     2,
+	"sent":        3,
+	"delivered":   4,
+	"read":        5,
+	"failed":      6,
+	"undelivered": 6,
+}
+
+// HandleTwilioStatus records a delivery receipt against the outbound message with that provider SID
+func HandleTwilioStatus(messageSID, status, errorCode, errorMessage string) error {
+	rank, ok := deliveryRank[status]
+	if !ok {
+		log.Printf("ℹ️ Ignoring Twilio status '%s' for %s", status, messageSID)
+		return nil
+	}
+
+	var msg models.OutboundMessage
+	if err := config.DB.Where("provider_id = ?", messageSID).First(&msg).Error; err != nil {
+		return errors.New("message not found")
+	}
+
+	if deliveryRank[msg.DeliveryStatus] >= rank {
+		return nil
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{"delivery_status": status}
+	switch status {
+	case "delivered":
+		updates["delivered_at"] = now
+	case "read":
+		updates["read_at"] = now
+		if msg.DeliveredAt == nil {
+			updates["delivered_at"] = now
+		}
+	case "failed", "undelivered":
+		updates["last_error"] = strings.TrimSpace("twilio " + errorCode + " " + errorMessage)
+		log.Printf("❌ Twilio reports %s for message #%d (%s): %s", status, msg.ID, messageSID, errorCode)
+	}
+
+	if err := config.DB.Model(&msg).Updates(updates).Error; err != nil {
+		return err
+	}
+
+	// e.g. the number is not on WhatsApp: try SMS or email instead
+	if status == "failed" || status == "undelivered" {
+		fallbackToNextChannel(msg)
+	}
+	return nil
+}
+
+// findTenantByPhone matches an inbound sender to the most recent tenant profile with that number.
+// Stored numbers are E.164, so normalizing the sender lets the lookup use the phone_number index.
+func findTenantByPhone(from string) (models.TenantProfile, error) {
+	var profile models.TenantProfile
+	phone, err := utils.NormalizePhone(from)
+	if err != nil {
+		return profile, errors.New("tenant not found")
+	}
+	err = config.DB.Where("phone_number = ?", phone).Order("id DESC").First(&profile).Error
+	if err != nil {
+		return profile, errors.New("tenant not found")
+	}
+	return profile, nil
+}
+
+// HandleInboundMessage stores a tenant's reply and links it to their profile.
+// Twilio retries webhooks, so a MessageSid we have already seen is returned as-is.
+func HandleInboundMessage(messageSID, from, to, body string, mediaURLs []string) (models.InboundMessage, models.TenantProfile, error) {
+	var existing models.InboundMessage
+	if err := config.DB.Where("provider_id = ?", messageSID).First(&existing).Error; err == nil {
+		return existing, models.TenantProfile{}, errors.New("duplicate message")
+	}
+
+	channel := notifier.SMS
+	if strings.HasPrefix(from, "whatsapp:") {
+		channel = notifier.WhatsApp
+	}
+
+	inbound := models.InboundMessage{
+		Channel:    channel,
+		From:       strings.TrimPrefix(from, "whatsapp:"),
+		To:         strings.TrimPrefix(to, "whatsapp:"),
+		Body:       body,
+		MediaURLs:  strings.Join(mediaURLs, "\n"),
+		ProviderID: messageSID,
+	}
+
+	profile, err := findTenantByPhone(inbound.From)
+	if err == nil {
+		inbound.PropertyID = profile.PropertyID
+		inbound.TenantID = profile.UserID
+	} else {
+		log.Printf("ℹ️ Inbound %s from unknown number %s", channel, inbound.From)
+	}
+
+	if err := config.DB.Create(&inbound).Error; err != nil {
+		return inbound, profile, errors.New("could not store inbound message")
+	}
+
+	log.Printf("📥 Inbound %s #%d from %s (tenant %d)", channel, inbound.ID, inbound.From, inbound.TenantID)
+	return inbound, profile, nil
+}
+
+// GetTenantInboundMessages returns a tenant's replies for the owner, newest first
+func GetTenantInboundMessages(tenantID, ownerID uint) ([]models.InboundMessage, error) {
+	var profile models.TenantProfile
+	if err := config.DB.Where("user_id = ?", tenantID).First(&profile).Error; err != nil {
+		return nil, errors.New("tenant not found")
+	}
+	if err := verifyPropertyOwner(profile.PropertyID, ownerID); err != nil {
+		return nil, err
+	}
+
+	var messages []models.InboundMessage
+	err := config.DB.Where("tenant_id = ?", tenantID).Order("created_at DESC").Find(&messages).Error
+	return messages, err
+}
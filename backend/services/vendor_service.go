@@ -0,0 +1,412 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"pg-manager-backend/notifier"
+	"pg-manager-backend/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// workOrderTransitions lists where each work order status may move next
+var workOrderTransitions = map[string][]string{
+	"requested": {"quoted", "approved", "cancelled"},
+	"quoted":    {"quoted", "approved", "cancelled"},
+	"approved":  {"completed", "cancelled"},
+	"completed": {},
+	"cancelled": {},
+}
+
+// VendorInput is what the owner fills in for a vendor
+type VendorInput struct {
+	Name   string   `json:"name" binding:"required"`
+	Phone  string   `json:"phone"`
+	Email  string   `json:"email"`
+	Trades []string `json:"trades"` // Complaint categories, e.g. ["Plumbing"]
+	Notes  string   `json:"notes"`
+	Active *bool    `json:"active"`
+}
+
+// WorkOrderInput opens a work order, for a complaint or for general upkeep
+type WorkOrderInput struct {
+	PropertyID  uint   `json:"property_id"`
+	ComplaintID *uint  `json:"complaint_id"`
+	VendorID    uint   `json:"vendor_id" binding:"required"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// WorkOrderCompletion closes a work order with what was actually paid
+type WorkOrderCompletion struct {
+	FinalAmount      float64 `json:"final_amount"`
+	Note             string  `json:"note"`
+	ResolveComplaint bool    `json:"resolve_complaint"` // Also mark the linked complaint resolved and notify the tenant
+}
+
+func applyVendorInput(vendor *models.Vendor, input VendorInput) error {
+	vendor.Name = strings.TrimSpace(input.Name)
+	if vendor.Name == "" {
+		return errors.New("invalid vendor: name is required")
+	}
+
+	vendor.Phone = ""
+	if phone := strings.TrimSpace(input.Phone); phone != "" {
+		normalized, err := utils.NormalizePhone(phone)
+		if err != nil {
+			return errors.New("invalid vendor phone")
+		}
+		vendor.Phone = normalized
+	}
+
+	var trades []string
+	for _, trade := range input.Trades {
+		if !containsString(complaintCategories, trade) {
+			return fmt.Errorf("invalid trade %q: use one of %s", trade, strings.Join(complaintCategories, ", "))
+		}
+		if !containsString(trades, trade) {
+			trades = append(trades, trade)
+		}
+	}
+	vendor.Trades = strings.Join(trades, ",")
+	vendor.Email = strings.TrimSpace(input.Email)
+	vendor.Notes = strings.TrimSpace(input.Notes)
+	if input.Active != nil {
+		vendor.Active = *input.Active
+	}
+	return nil
+}
+
+// CreateVendor adds a vendor to the owner's directory
+func CreateVendor(ownerID uint, input VendorInput) (models.Vendor, error) {
+	vendor := models.Vendor{OwnerID: ownerID, Active: true}
+	if err := applyVendorInput(&vendor, input); err != nil {
+		return vendor, err
+	}
+	err := config.DB.Create(&vendor).Error
+	return vendor, err
+}
+
+// UpdateVendor edits a vendor; set active to false to retire one without losing their work orders
+func UpdateVendor(vendorID, ownerID uint, input VendorInput) (models.Vendor, error) {
+	vendor, err := loadOwnedVendor(vendorID, ownerID)
+	if err != nil {
+		return vendor, err
+	}
+	if err := applyVendorInput(&vendor, input); err != nil {
+		return vendor, err
+	}
+	err = config.DB.Save(&vendor).Error
+	return vendor, err
+}
+
+// GetVendors lists the owner's vendors, optionally only active ones handling a trade
+func GetVendors(ownerID uint, trade string, activeOnly bool) ([]models.Vendor, error) {
+	query := config.DB.Where("owner_id = ?", ownerID)
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	var vendors []models.Vendor
+	if err := query.Order("name ASC").Find(&vendors).Error; err != nil {
+		return nil, err
+	}
+	if trade == "" {
+		return vendors, nil
+	}
+
+	matching := []models.Vendor{}
+	for _, vendor := range vendors {
+		if containsString(strings.Split(vendor.Trades, ","), trade) {
+			matching = append(matching, vendor)
+		}
+	}
+	return matching, nil
+}
+
+func loadOwnedVendor(vendorID, ownerID uint) (models.Vendor, error) {
+	var vendor models.Vendor
+	if err := config.DB.Where("id = ? AND owner_id = ?", vendorID, ownerID).First(&vendor).Error; err != nil {
+		return vendor, errors.New("vendor not found")
+	}
+	return vendor, nil
+}
+
+// loadOwnedWorkOrder fetches a work order the owner is allowed to act on
+func loadOwnedWorkOrder(workOrderID, ownerID uint) (models.WorkOrder, error) {
+	var order models.WorkOrder
+	if err := config.DB.Preload("Vendor").First(&order, workOrderID).Error; err != nil {
+		return order, errors.New("work order not found")
+	}
+	if err := verifyPropertyOwner(order.PropertyID, ownerID); err != nil {
+		return order, err
+	}
+	return order, nil
+}
+
+// messageVendor sends a vendor a WhatsApp about their work order, if we have their number
+func messageVendor(order models.WorkOrder, body string) {
+	if order.Vendor.Phone == "" {
+		return
+	}
+	if _, err := enqueueMessage(notifier.Message{Channel: notifier.WhatsApp, To: order.Vendor.Phone, Body: body},
+		order.PropertyID, 0, "work_order"); err != nil {
+		log.Printf("⚠️ Could not message vendor about work order #%d: %v", order.ID, err)
+	}
+}
+
+// CreateWorkOrder gives a job to a vendor. For a complaint, the vendor also becomes its assignee and the
+// title and description default to the complaint's.
+func CreateWorkOrder(ownerID uint, input WorkOrderInput) (models.WorkOrder, error) {
+	vendor, err := loadOwnedVendor(input.VendorID, ownerID)
+	if err != nil {
+		return models.WorkOrder{}, err
+	}
+	if !vendor.Active {
+		return models.WorkOrder{}, errors.New("invalid vendor: vendor is inactive")
+	}
+
+	order := models.WorkOrder{
+		PropertyID:  input.PropertyID,
+		VendorID:    vendor.ID,
+		Vendor:      vendor,
+		Title:       strings.TrimSpace(input.Title),
+		Description: strings.TrimSpace(input.Description),
+		Status:      "requested",
+	}
+
+	// 1. A complaint decides the property and fills in the job
+	var complaint models.Complaint
+	if input.ComplaintID != nil {
+		if complaint, err = loadOwnedComplaint(*input.ComplaintID, ownerID); err != nil {
+			return models.WorkOrder{}, err
+		}
+		if !containsString(openComplaintStatuses, complaint.Status) {
+			return models.WorkOrder{}, errors.New("invalid complaint: it is already resolved or closed")
+		}
+		order.ComplaintID = &complaint.ID
+		order.PropertyID = complaint.PropertyID
+		if order.Title == "" {
+			order.Title = fmt.Sprintf("%s - Room %s (complaint #%d)", complaint.Category, complaint.RoomNo, complaint.ID)
+		}
+		if order.Description == "" {
+			order.Description = complaint.Description
+		}
+	} else if err := verifyPropertyOwner(order.PropertyID, ownerID); err != nil {
+		return models.WorkOrder{}, err
+	}
+	if order.Title == "" {
+		return models.WorkOrder{}, errors.New("invalid work order: title is required")
+	}
+
+	// 2. Save it and hand the complaint to the vendor together
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Vendor").Create(&order).Error; err != nil {
+			return err
+		}
+		if order.ComplaintID == nil {
+			return nil
+		}
+		return assignComplaint(tx, &complaint, ownerID, "vendor", vendor.Name, vendor.Phone, fmt.Sprintf("Work order #%d", order.ID))
+	})
+	if err != nil {
+		return models.WorkOrder{}, err
+	}
+
+	property := loadProperty(order.PropertyID)
+	messageVendor(order, fmt.Sprintf("🛠️ New job from %s (work order #%d)\n%s\n%s\nAddress: %s\nPlease reply with your quote.",
+		property.Name, order.ID, order.Title, order.Description, property.Address))
+	return order, nil
+}
+
+// GetWorkOrders lists a property's work orders, newest first
+func GetWorkOrders(propertyID, ownerID uint, status string, complaintID uint) ([]models.WorkOrder, error) {
+	if err := verifyPropertyOwner(propertyID, ownerID); err != nil {
+		return nil, err
+	}
+	query := config.DB.Preload("Vendor").Where("property_id = ?", propertyID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if complaintID != 0 {
+		query = query.Where("complaint_id = ?", complaintID)
+	}
+	var orders []models.WorkOrder
+	err := query.Order("created_at desc").Find(&orders).Error
+	return orders, err
+}
+
+func moveWorkOrder(order *models.WorkOrder, status string) error {
+	if !containsString(workOrderTransitions[order.Status], status) {
+		return fmt.Errorf("invalid status change: %s → %s", order.Status, status)
+	}
+	return nil
+}
+
+// saveWorkOrderMove writes a status change only while the order is still in the status it was loaded
+// with, so of two owners acting at once only one wins. The order is reloaded afterwards.
+func saveWorkOrderMove(order *models.WorkOrder, updates map[string]interface{}) error {
+	moved := config.DB.Model(&models.WorkOrder{}).Where("id = ? AND status = ?", order.ID, order.Status).Updates(updates)
+	if moved.Error != nil {
+		return moved.Error
+	}
+	if moved.RowsAffected != 1 {
+		return fmt.Errorf("invalid status change: work order is no longer %s", order.Status)
+	}
+	return config.DB.Preload("Vendor").First(order, order.ID).Error
+}
+
+// RecordWorkOrderQuote stores the vendor's quote; a revised quote replaces the earlier one
+func RecordWorkOrderQuote(workOrderID, ownerID uint, amount float64, note string) (models.WorkOrder, error) {
+	order, err := loadOwnedWorkOrder(workOrderID, ownerID)
+	if err != nil {
+		return order, err
+	}
+	if err := moveWorkOrder(&order, "quoted"); err != nil {
+		return order, err
+	}
+	if amount <= 0 {
+		return order, errors.New("invalid quote: amount must be positive")
+	}
+
+	amount = roundPaise(amount)
+	now := time.Now()
+	err = saveWorkOrderMove(&order, map[string]interface{}{
+		"status":       "quoted",
+		"quote_amount": amount,
+		"quote_note":   strings.TrimSpace(note),
+		"quoted_at":    now,
+	})
+	return order, err
+}
+
+// ApproveWorkOrder lets the vendor start; a linked complaint moves to in progress
+func ApproveWorkOrder(workOrderID, ownerID uint) (models.WorkOrder, error) {
+	order, err := loadOwnedWorkOrder(workOrderID, ownerID)
+	if err != nil {
+		return order, err
+	}
+	if err := moveWorkOrder(&order, "approved"); err != nil {
+		return order, err
+	}
+
+	if err := saveWorkOrderMove(&order, map[string]interface{}{"status": "approved", "approved_at": time.Now()}); err != nil {
+		return order, err
+	}
+
+	if order.ComplaintID != nil {
+		var complaint models.Complaint
+		if config.DB.First(&complaint, *order.ComplaintID).Error == nil && containsString(complaintTransitions[complaint.Status], "in_progress") {
+			if err := changeComplaintStatus(&complaint, "in_progress", fmt.Sprintf("Work order #%d approved", order.ID), ownerID, actorName(ownerID)); err != nil {
+				log.Printf("⚠️ Could not move complaint #%d to in progress: %v", complaint.ID, err)
+			}
+		}
+	}
+
+	body := fmt.Sprintf("✅ Work order #%d approved: %s", order.ID, order.Title)
+	if order.QuoteAmount != nil {
+		body += fmt.Sprintf("\nApproved amount: ₹%.2f", *order.QuoteAmount)
+	}
+	messageVendor(order, body)
+	return order, nil
+}
+
+// CompleteWorkOrder closes the job and books its cost as an Expenditure linked to the work order and
+// complaint. The final amount defaults to the quote.
+func CompleteWorkOrder(workOrderID, ownerID uint, input WorkOrderCompletion) (models.WorkOrder, error) {
+	order, err := loadOwnedWorkOrder(workOrderID, ownerID)
+	if err != nil {
+		return order, err
+	}
+	if err := moveWorkOrder(&order, "completed"); err != nil {
+		return order, err
+	}
+
+	amount := input.FinalAmount
+	if amount == 0 && order.QuoteAmount != nil {
+		amount = *order.QuoteAmount
+	}
+	if amount < 0 {
+		return order, errors.New("invalid amount: cannot be negative")
+	}
+	amount = roundPaise(amount)
+	now := time.Now()
+
+	// 1. Close the order and book the expense together. The status guard lets only one of two
+	// concurrent completions through, so the expense is never booked twice.
+	err = config.DB.Transaction(func(tx *gorm.DB) error {
+		closed := tx.Model(&models.WorkOrder{}).Where("id = ? AND status = ?", order.ID, "approved").Updates(map[string]interface{}{
+			"status":          "completed",
+			"final_amount":    amount,
+			"completion_note": strings.TrimSpace(input.Note),
+			"completed_at":    now,
+		})
+		if closed.Error != nil {
+			return closed.Error
+		}
+		if closed.RowsAffected != 1 {
+			return errors.New("invalid status change: work order is no longer approved")
+		}
+		if amount > 0 {
+			expense := models.Expenditure{
+				PropertyID:  order.PropertyID,
+				Amount:      amount,
+				Category:    "Repairs",
+				Description: fmt.Sprintf("Work order #%d: %s (%s)", order.ID, order.Title, order.Vendor.Name),
+				Date:        now,
+				ComplaintID: order.ComplaintID,
+				WorkOrderID: &order.ID,
+			}
+			if err := tx.Create(&expense).Error; err != nil {
+				return err
+			}
+			return tx.Model(&models.WorkOrder{}).Where("id = ?", order.ID).Update("expenditure_id", expense.ID).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return order, err
+	}
+
+	// 2. Optionally resolve the complaint, which also tells the tenant
+	if input.ResolveComplaint && order.ComplaintID != nil {
+		comment := fmt.Sprintf("Fixed by %s (work order #%d)", order.Vendor.Name, order.ID)
+		if _, err := UpdateComplaintStatus(*order.ComplaintID, ownerID, "resolved", comment); err != nil {
+			log.Printf("⚠️ Work order #%d completed but complaint #%d not resolved: %v", order.ID, *order.ComplaintID, err)
+		}
+	}
+
+	config.DB.Preload("Vendor").First(&order, order.ID)
+	return order, nil
+}
+
+// CancelWorkOrder drops a job that has not been completed
+func CancelWorkOrder(workOrderID, ownerID uint) (models.WorkOrder, error) {
+	order, err := loadOwnedWorkOrder(workOrderID, ownerID)
+	if err != nil {
+		return order, err
+	}
+	if err := moveWorkOrder(&order, "cancelled"); err != nil {
+		return order, err
+	}
+	if err := saveWorkOrderMove(&order, map[string]interface{}{"status": "cancelled"}); err != nil {
+		return order, err
+	}
+	messageVendor(order, fmt.Sprintf("❌ Work order #%d (%s) has been cancelled.", order.ID, order.Title))
+	return order, nil
+}
+
+// complaintCosts is every expense booked against a complaint, with their total
+func complaintCosts(complaintID uint) ([]models.Expenditure, float64) {
+	expenses := []models.Expenditure{}
+	config.DB.Where("complaint_id = ?", complaintID).Order("date asc").Find(&expenses)
+	var total float64
+	for _, expense := range expenses {
+		total += expense.Amount
+	}
+	return expenses, roundPaise(total)
+}
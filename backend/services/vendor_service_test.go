@@ -0,0 +1,33 @@
+package services
+
+import (
+	"pg-manager-backend/config"
+	"pg-manager-backend/models"
+	"testing"
+)
+
+func TestStaleWorkOrderMoveIsRejected(t *testing.T) {
+	requireDB(t)
+	property, _ := createTestTenant(t, models.TenantProfile{Name: "Ravi"})
+	vendor := models.Vendor{OwnerID: property.OwnerID, Name: "Suresh Plumbing"}
+	config.DB.Create(&vendor)
+	order := models.WorkOrder{PropertyID: property.ID, VendorID: vendor.ID, Title: "Fix tap", Status: "requested"}
+	config.DB.Create(&order)
+
+	// The owner cancels from a page loaded before someone else approved the job
+	stale, err := loadOwnedWorkOrder(order.ID, property.OwnerID)
+	if err != nil {
+		t.Fatalf("loadOwnedWorkOrder: %v", err)
+	}
+	if _, err := ApproveWorkOrder(order.ID, property.OwnerID); err != nil {
+		t.Fatalf("ApproveWorkOrder: %v", err)
+	}
+	if err := saveWorkOrderMove(&stale, map[string]interface{}{"status": "cancelled"}); err == nil {
+		t.Fatal("cancelled a work order that had moved on since it was loaded")
+	}
+
+	config.DB.First(&order, order.ID)
+	if order.Status != "approved" {
+		t.Errorf("status %s, want approved", order.Status)
+	}
+}
@@ -6,14 +6,15 @@ import (
 	"log"
 	"pg-manager-backend/config"
 	"pg-manager-backend/models"
-	"pg-manager-backend/utils"
 	"strings" // Required for splitting the reference_id
-	"time"
 )
 
 func HandleRazorpayPayment(payload map[string]interface{}) error {
 	// 1. Filter for the specific 'payment_link.paid' event
 	event, _ := payload["event"].(string)
+	if event == "payment.failed" {
+		return handleRazorpayFailure(payload)
+	}
 	if event != "payment_link.paid" {
 		log.Printf("ℹ️ Webhook received for non-billable event: %s", event)
 		return nil
@@ -59,26 +60,18 @@ func HandleRazorpayPayment(payload map[string]interface{}) error {
 		return fmt.Errorf("tenant profile with UserID %s not found", actualUserID)
 	}
 
-	// Calculate and update the new balance
-	newBalance := profile.Balance - amountInRupees
-	if err := tx.Model(&profile).Update("balance", newBalance).Error; err != nil {
-		tx.Rollback()
-		return errors.New("failed to update tenant balance")
-	}
-
-	// 5. Create a record in the Payment table
+	// 5. Update the balance and create a record in the Payment table
 	paymentRecord := models.Payment{
-		TenantID:    profile.UserID,
-		PropertyID:  profile.PropertyID,
 		Amount:      amountInRupees,
 		PaymentType: "Rent-Payment",
 		Method:      "Razorpay-Online",
-		Date:        time.Now(),
+		Reference:   referenceID,
 	}
 
-	if err := tx.Create(&paymentRecord).Error; err != nil {
+	newBalance, err := applyPayment(tx, &profile, &paymentRecord, nil)
+	if err != nil {
 		tx.Rollback()
-		return errors.New("failed to insert payment record into history")
+		return fmt.Errorf("failed to record payment: %v", err)
 	}
 
 	// 6. Commit the transaction
@@ -86,34 +79,36 @@ func HandleRazorpayPayment(payload map[string]interface{}) error {
 		return errors.New("transaction commit failed")
 	}
 
-	// 7. Post-Payment Automation (Receipt & Terminal Log)
-	go func() {
-		log.Printf("✅ Payment successfully processed for %s.", profile.Name)
-
-		fileName, err := utils.GenerateReceipt(paymentRecord, profile.Name)
-		receiptURL := ""
-		if err == nil {
-			receiptURL = fmt.Sprintf("%s/receipts/%s", config.App.BaseURL, fileName)
-		} else {
-			log.Printf("⚠️ Receipt Generation Failed: %v", err)
-		}
-
-		// TERMINAL LOGGING (Simulating WhatsApp Message)
-		fmt.Println("\n--- [TERMINAL WHATSAPP SIMULATION: PAYMENT SUCCESS] ---")
-		fmt.Printf("To: %s (%s)\n", profile.Name, profile.PhoneNumber)
-		fmt.Printf("Message: ✅ Payment Successful!\n"+
-			"Namaste %s, your payment of ₹%.2f was received.\n"+
-			"Current Balance: ₹%.2f.\n"+
-			"Download Receipt: %s\n",
-			profile.Name, amountInRupees, newBalance, receiptURL)
-
-		// WhatsApp Part Commented Out as requested
-		/*
-			msg := fmt.Sprintf("✅ *Payment Successful!*\\n\\nNamaste %s, your payment of ₹%.2f via Razorpay was received.\\n*Current Balance:* ₹%.2f.\\n\\n📄 Download Receipt: %s",
-				profile.Name, amountInRupees, newBalance, receiptURL)
-			utils.SendWhatsAppMessage(profile.PhoneNumber, msg)
-		*/
-	}()
+	// 7. Post-Payment Automation (Receipt & queued confirmation)
+	log.Printf("✅ Payment successfully processed for %s.", profile.Name)
+	sendPaymentConfirmation(paymentRecord, profile, newBalance)
+	alertOwner(profile.PropertyID, AlertPaymentReceived, "Payment Received",
+		fmt.Sprintf("💰 %s paid ₹%.2f online. New balance: ₹%.2f", profile.Name, amountInRupees, newBalance))
+
+	return nil
+}
+
+// handleRazorpayFailure alerts the owner when a tenant's online payment attempt fails
+func handleRazorpayFailure(payload map[string]interface{}) error {
+	data, _ := payload["payload"].(map[string]interface{})
+	payment, _ := data["payment"].(map[string]interface{})
+	entity, ok := payment["entity"].(map[string]interface{})
+	if !ok {
+		return errors.New("invalid webhook payload: missing 'payment.entity' field")
+	}
+
+	amountPaise, _ := entity["amount"].(float64)
+	contact, _ := entity["contact"].(string)
+	reason, _ := entity["error_description"].(string)
+
+	profile, err := findTenantByPhone(contact)
+	if err != nil {
+		log.Printf("⚠️ Razorpay payment failure from unknown contact %s: %s", contact, reason)
+		return nil
+	}
 
+	log.Printf("❌ Razorpay payment of ₹%.2f failed for %s: %s", amountPaise/100, profile.Name, reason)
+	alertOwner(profile.PropertyID, AlertPaymentFailed, "Payment Failed",
+		fmt.Sprintf("❌ %s's online payment of ₹%.2f failed.\nReason: %s", profile.Name, amountPaise/100, reason))
 	return nil
 }
@@ -0,0 +1,327 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// StatementCredit is one incoming transfer parsed from a bank export
+type StatementCredit struct {
+	Date        time.Time
+	Amount      float64
+	Description string
+	Reference   string
+	PayerName   string
+}
+
+var (
+	upiUTRPattern  = regexp.MustCompile(`\b(\d{12})\b`)
+	neftUTRPattern = regexp.MustCompile(`(?i)(?:NEFT|RTGS|IMPS)[-/: ]*([A-Z0-9]{11,22})`)
+	ofxTxnPattern  = regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+
+	statementDateLayouts = []string{
+		"02/01/2006", "02-01-2006", "2006-01-02", "02-Jan-2006", "02 Jan 2006",
+		"02/01/06", "02-01-06", "02-Jan-06", "2/1/2006", "02.01.2006",
+	}
+
+	// Words that show up in narrations but are never part of a payer's name
+	narrationNoise = map[string]bool{
+		"UPI": true, "NEFT": true, "IMPS": true, "RTGS": true, "TRANSFER": true, "CR": true,
+		"BY": true, "FROM": true, "TO": true, "PAYMENT": true, "INB": true, "MOB": true, "RENT": true,
+	}
+)
+
+// ParseBankStatement detects CSV or OFX and returns only the credit lines
+func ParseBankStatement(fileName string, data []byte) ([]StatementCredit, string, error) {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if ext == ".ofx" || ext == ".qfx" || bytes.Contains(bytes.ToUpper(data[:min(len(data), 512)]), []byte("<OFX>")) {
+		credits, err := parseOFXStatement(data)
+		return credits, "ofx", err
+	}
+	credits, err := parseCSVStatement(data)
+	return credits, "csv", err
+}
+
+// parseCSVStatement handles the usual Indian bank exports: a few preamble rows, then a header row
+func parseCSVStatement(data []byte) ([]StatementCredit, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Find the header row within the first few lines
+	headerIdx := -1
+	var dateCol, descCol, creditCol, amountCol, typeCol, refCol = -1, -1, -1, -1, -1, -1
+	for i := 0; i < len(rows) && i < 25; i++ {
+		dateCol, descCol, creditCol, amountCol, typeCol, refCol = -1, -1, -1, -1, -1, -1
+		for j, cell := range rows[i] {
+			h := strings.ToLower(strings.TrimSpace(cell))
+			switch {
+			case strings.Contains(h, "date") && !strings.Contains(h, "value") && dateCol == -1:
+				dateCol = j
+			case strings.Contains(h, "narration") || strings.Contains(h, "description") ||
+				strings.Contains(h, "particulars") || strings.Contains(h, "remarks"):
+				descCol = j
+			case strings.Contains(h, "credit") || strings.Contains(h, "deposit"):
+				creditCol = j
+			case h == "amount" || strings.HasPrefix(h, "amount"):
+				amountCol = j
+			case h == "type" || h == "dr/cr" || h == "cr/dr":
+				typeCol = j
+			case strings.Contains(h, "ref") || strings.Contains(h, "utr") || strings.Contains(h, "chq"):
+				refCol = j
+			}
+		}
+		if dateCol >= 0 && (creditCol >= 0 || amountCol >= 0) {
+			headerIdx = i
+			break
+		}
+	}
+	if headerIdx == -1 {
+		return nil, errors.New("could not find a header row with date and credit/amount columns")
+	}
+
+	cell := func(row []string, idx int) string {
+		if idx < 0 || idx >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[idx])
+	}
+
+	// 2. Keep only credits
+	var credits []StatementCredit
+	for _, row := range rows[headerIdx+1:] {
+		date, ok := parseStatementDate(cell(row, dateCol))
+		if !ok {
+			continue // Footer rows, opening balance lines, etc.
+		}
+
+		var amount float64
+		if creditCol >= 0 {
+			amount = parseStatementAmount(cell(row, creditCol))
+		} else {
+			amount = parseStatementAmount(cell(row, amountCol))
+			txnType := strings.ToUpper(cell(row, typeCol))
+			if typeCol >= 0 && !strings.HasPrefix(txnType, "C") {
+				continue
+			}
+		}
+		if amount <= 0 {
+			continue
+		}
+
+		desc := cell(row, descCol)
+		ref := cell(row, refCol)
+		if utr := ExtractUTR(desc); utr != "" {
+			ref = utr
+		}
+
+		credits = append(credits, StatementCredit{
+			Date:        date,
+			Amount:      amount,
+			Description: desc,
+			Reference:   ref,
+			PayerName:   ExtractPayerName(desc),
+		})
+	}
+	return credits, nil
+}
+
+// parseOFXStatement reads the <STMTTRN> blocks of an OFX 1.x (SGML) or 2.x (XML) file
+func parseOFXStatement(data []byte) ([]StatementCredit, error) {
+	blocks := ofxTxnPattern.FindAllSubmatch(data, -1)
+	if len(blocks) == 0 {
+		return nil, errors.New("no transactions found in OFX file")
+	}
+
+	var credits []StatementCredit
+	for _, block := range blocks {
+		body := string(block[1])
+		amount, err := strconv.ParseFloat(ofxTag(body, "TRNAMT"), 64)
+		if err != nil || amount <= 0 {
+			continue
+		}
+
+		posted := ofxTag(body, "DTPOSTED")
+		if len(posted) < 8 {
+			continue
+		}
+		date, err := time.Parse("20060102", posted[:8])
+		if err != nil {
+			continue
+		}
+
+		name := ofxTag(body, "NAME")
+		memo := ofxTag(body, "MEMO")
+		desc := strings.TrimSpace(name + " " + memo)
+
+		ref := ExtractUTR(desc)
+		if ref == "" {
+			ref = ofxTag(body, "REFNUM")
+		}
+		if ref == "" {
+			ref = ofxTag(body, "FITID")
+		}
+
+		payer := ExtractPayerName(name)
+		if payer == "" {
+			payer = name
+		}
+
+		credits = append(credits, StatementCredit{
+			Date:        date,
+			Amount:      amount,
+			Description: desc,
+			Reference:   ref,
+			PayerName:   payer,
+		})
+	}
+	return credits, nil
+}
+
+// ofxTag returns the value of an OFX element, whether or not it has a closing tag
+func ofxTag(body, tag string) string {
+	upper := strings.ToUpper(body)
+	start := strings.Index(upper, "<"+tag+">")
+	if start == -1 {
+		return ""
+	}
+	value := body[start+len(tag)+2:]
+	if end := strings.IndexAny(value, "<\r\n"); end != -1 {
+		value = value[:end]
+	}
+	return strings.TrimSpace(value)
+}
+
+func parseStatementDate(value string) (time.Time, bool) {
+	for _, layout := range statementDateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func parseStatementAmount(value string) float64 {
+	clean := strings.NewReplacer(",", "", "₹", "", "INR", "", " ", "").Replace(strings.ToUpper(value))
+	clean = strings.TrimSuffix(clean, "CR")
+	amount, err := strconv.ParseFloat(clean, 64)
+	if err != nil {
+		return 0
+	}
+	return amount
+}
+
+// ExtractUTR pulls a UPI RRN or NEFT/IMPS/RTGS UTR out of a bank narration
+func ExtractUTR(narration string) string {
+	if m := neftUTRPattern.FindStringSubmatch(narration); m != nil {
+		return strings.ToUpper(m[1])
+	}
+	if m := upiUTRPattern.FindStringSubmatch(narration); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// ExtractPayerName picks the most name-like segment of a narration such as "UPI/312345678901/RAVI KUMAR/ravi@okaxis"
+func ExtractPayerName(narration string) string {
+	segments := strings.FieldsFunc(narration, func(r rune) bool {
+		return r == '/' || r == '-' || r == ':' || r == '|'
+	})
+
+	best := ""
+	for _, seg := range segments {
+		seg = strings.TrimSpace(seg)
+		if seg == "" || strings.Contains(seg, "@") || narrationNoise[strings.ToUpper(seg)] {
+			continue
+		}
+		letters := 0
+		nameLike := true
+		for _, r := range seg {
+			if unicode.IsLetter(r) {
+				letters++
+			} else if r != ' ' && r != '.' {
+				nameLike = false
+				break
+			}
+		}
+		if nameLike && letters >= 3 && len(seg) > len(best) {
+			best = seg
+		}
+	}
+	return best
+}
+
+// NameSimilarity scores two person names from 0 to 1, tolerating initials and word order
+func NameSimilarity(a, b string) float64 {
+	tokensA := nameTokens(a)
+	tokensB := nameTokens(b)
+	if len(tokensA) == 0 || len(tokensB) == 0 {
+		return 0
+	}
+
+	// 1. Token overlap; a single-letter token matches any word with that initial
+	matched := 0
+	for _, ta := range tokensA {
+		for _, tb := range tokensB {
+			if ta == tb || (len(ta) == 1 && strings.HasPrefix(tb, ta)) || (len(tb) == 1 && strings.HasPrefix(ta, tb)) {
+				matched++
+				break
+			}
+		}
+	}
+	overlap := float64(matched) / float64(min(len(tokensA), len(tokensB)))
+
+	// 2. Edit distance on the joined names catches typos like "Shreyas" vs "Sreyas"
+	joinedA := strings.Join(tokensA, "")
+	joinedB := strings.Join(tokensB, "")
+	longest := max(len(joinedA), len(joinedB))
+	editScore := 1 - float64(levenshtein(joinedA, joinedB))/float64(longest)
+
+	return max(overlap, editScore)
+}
+
+func nameTokens(name string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	tokens := fields[:0]
+	for _, f := range fields {
+		if !narrationNoise[strings.ToUpper(f)] {
+			tokens = append(tokens, f)
+		}
+	}
+	return tokens
+}
+
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
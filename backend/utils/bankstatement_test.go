@@ -0,0 +1,135 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseBankStatementCSV(t *testing.T) {
+	// HDFC-style export: preamble rows, separate withdrawal and deposit columns, a footer
+	csvData := `HDFC BANK Ltd.,,,,,,
+Statement of account,,,,,,
+Date,Narration,Chq./Ref.No.,Value Dt,Withdrawal Amt.,Deposit Amt.,Closing Balance
+01/01/25,UPI/412345678901/RAVI KUMAR/ravi@okaxis,0000412345678901,01/01/25,,"8,000.00","58,000.00"
+02/01/25,ATM WDL KORAMANGALA,000000000123,02/01/25,"2,000.00",,"56,000.00"
+03/01/25,NEFT-SBIN0000123456-PRIYA SHARMA-RENT JAN,SBIN0000123456,03/01/25,,"7,500.00","63,500.00"
+05/01/25,CASH DEPOSIT,,05/01/25,,"8,000.00","71,500.00"
+05/01/25,CASH DEPOSIT,,05/01/25,,"8,000.00","79,500.00"
+,,,,,,
+Opening Balance,,,,,,
+`
+	credits, format, err := ParseBankStatement("statement.csv", []byte(csvData))
+	if err != nil {
+		t.Fatalf("ParseBankStatement: %v", err)
+	}
+	if format != "csv" {
+		t.Errorf("format = %s, want csv", format)
+	}
+
+	want := []StatementCredit{
+		{Date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Amount: 8000, Description: "UPI/412345678901/RAVI KUMAR/ravi@okaxis", Reference: "412345678901", PayerName: "RAVI KUMAR"},
+		{Date: time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC), Amount: 7500, Description: "NEFT-SBIN0000123456-PRIYA SHARMA-RENT JAN", Reference: "SBIN0000123456", PayerName: "PRIYA SHARMA"},
+		{Date: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), Amount: 8000, Description: "CASH DEPOSIT", PayerName: "CASH DEPOSIT"},
+		{Date: time.Date(2025, 1, 5, 0, 0, 0, 0, time.UTC), Amount: 8000, Description: "CASH DEPOSIT", PayerName: "CASH DEPOSIT"},
+	}
+	assertCredits(t, credits, want)
+}
+
+func TestParseBankStatementCSVWithTypeColumn(t *testing.T) {
+	// Single amount column with a Dr/Cr marker, as SBI and ICICI export it
+	csvData := `Txn Date,Description,Ref No,Amount,Dr/Cr
+2025-01-04,IMPS/P2A/501234567890/ANITA,501234567890,"12,500.00",CR
+2025-01-04,Electricity bill,BESCOM123,"3,200.00",DR
+`
+	credits, _, err := ParseBankStatement("export.csv", []byte(csvData))
+	if err != nil {
+		t.Fatalf("ParseBankStatement: %v", err)
+	}
+	assertCredits(t, credits, []StatementCredit{
+		{Date: time.Date(2025, 1, 4, 0, 0, 0, 0, time.UTC), Amount: 12500, Description: "IMPS/P2A/501234567890/ANITA", Reference: "501234567890", PayerName: "ANITA"},
+	})
+}
+
+func TestParseBankStatementOFX(t *testing.T) {
+	// OFX 1.x SGML: no closing tags on the leaf elements
+	ofx := `OFXHEADER:100
+DATA:OFXSGML
+<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20250106120000
+<TRNAMT>9000.00
+<FITID>F0001
+<NAME>UPI-SURESH BABU
+<MEMO>412345678902 rent
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20250107
+<TRNAMT>-450.00
+<FITID>F0002
+<NAME>WATER CAN
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20250108
+<TRNAMT>6000.00
+<FITID>F0003
+<NAME>MEENA
+</STMTTRN>
+</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>
+`
+	credits, format, err := ParseBankStatement("statement.qfx", []byte(ofx))
+	if err != nil {
+		t.Fatalf("ParseBankStatement: %v", err)
+	}
+	if format != "ofx" {
+		t.Errorf("format = %s, want ofx", format)
+	}
+	assertCredits(t, credits, []StatementCredit{
+		{Date: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), Amount: 9000, Description: "UPI-SURESH BABU 412345678902 rent", Reference: "412345678902", PayerName: "SURESH BABU"},
+		{Date: time.Date(2025, 1, 8, 0, 0, 0, 0, time.UTC), Amount: 6000, Description: "MEENA", Reference: "F0003", PayerName: "MEENA"},
+	})
+}
+
+func TestParseBankStatementRejectsUnknownLayout(t *testing.T) {
+	if _, _, err := ParseBankStatement("notes.csv", []byte("hello,world\n1,2\n")); err == nil {
+		t.Error("want an error for a CSV without date and amount columns")
+	}
+	if _, _, err := ParseBankStatement("empty.ofx", []byte("<OFX></OFX>")); err == nil {
+		t.Error("want an error for an OFX file without transactions")
+	}
+}
+
+func TestNameSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		min  float64
+		max  float64
+	}{
+		{"RAVI KUMAR", "Ravi Kumar", 1, 1},
+		{"KUMAR RAVI", "Ravi Kumar", 1, 1},
+		{"R KUMAR", "Ravi Kumar", 1, 1},
+		{"SREYAS", "Shreyas", 0.8, 0.9},
+		{"PRIYA SHARMA", "Ravi Kumar", 0, 0.3},
+		{"", "Ravi Kumar", 0, 0},
+	}
+	for _, tt := range tests {
+		if got := NameSimilarity(tt.a, tt.b); got < tt.min || got > tt.max {
+			t.Errorf("NameSimilarity(%q, %q) = %.2f, want %.2f..%.2f", tt.a, tt.b, got, tt.min, tt.max)
+		}
+	}
+}
+
+func assertCredits(t *testing.T, got, want []StatementCredit) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d credits, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if !got[i].Date.Equal(want[i].Date) || got[i].Amount != want[i].Amount || got[i].Description != want[i].Description ||
+			got[i].Reference != want[i].Reference || got[i].PayerName != want[i].PayerName {
+			t.Errorf("credit %d:\n got %+v\nwant %+v", i, got[i], want[i])
+		}
+	}
+}
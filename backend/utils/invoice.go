@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"bytes"
+	"pg-manager-backend/models"
+	"strconv"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// GenerateInvoice renders an A5 invoice and, when the property has a UPI VPA, a scan-to-pay QR
+func GenerateInvoice(invoice models.Invoice, tenantName string, property models.Property, head Letterhead) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A5", "")
+	pdf.AddPage()
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	// 1. Branding
+	drawLetterhead(pdf, head, tr)
+
+	pdf.SetFont("Arial", "B", 16)
+	pdf.SetTextColor(24, 144, 255)
+	pdf.Cell(0, 10, "RENT INVOICE")
+	pdf.Ln(12)
+
+	// 2. Header
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Arial", "", 10)
+	if invoice.Number != "" {
+		pdf.Cell(0, 10, "Invoice No: "+invoice.Number)
+		pdf.Ln(6)
+		pdf.Cell(0, 10, "UPI Reference: "+invoice.Reference)
+	} else {
+		pdf.Cell(0, 10, "Invoice No: "+invoice.Reference)
+	}
+	pdf.Ln(6)
+	pdf.Cell(0, 10, "Due Date: "+invoice.DueDate.Format("02-Jan-2006"))
+	pdf.Ln(10)
+
+	pdf.SetFillColor(245, 247, 250)
+	pdf.CellFormat(0, 10, "Invoice Details", "1", 1, "L", true, 0, "")
+
+	pdf.Cell(0, 10, tr("Tenant Name: "+tenantName))
+	pdf.Ln(8)
+	pdf.Cell(0, 10, tr("Particulars: "+invoice.Description))
+	pdf.Ln(8)
+
+	amountDue := invoice.Amount - invoice.AmountPaid
+	if invoice.AmountPaid >= 0.01 {
+		pdf.Cell(0, 10, "Invoice Amount: INR "+strconv.FormatFloat(invoice.Amount, 'f', 2, 64))
+		pdf.Ln(8)
+		pdf.Cell(0, 10, "Less: Advance / Paid: INR "+strconv.FormatFloat(invoice.AmountPaid, 'f', 2, 64))
+		pdf.Ln(8)
+	}
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(0, 10, "Amount Due: INR "+strconv.FormatFloat(amountDue, 'f', 2, 64))
+	pdf.Ln(14)
+
+	// 3. UPI QR for the exact amount still due
+	if property.UPIVPA != "" && amountDue >= 0.01 {
+		intent := BuildUPIIntent(property.UPIVPA, property.UPIPayeeName, amountDue, invoice.Reference, invoice.Description)
+		qrPNG, err := GenerateUPIQR(intent, 512)
+		if err != nil {
+			return nil, err
+		}
+
+		pdf.SetFont("Arial", "", 10)
+		pdf.Cell(0, 8, "Scan with any UPI app to pay "+property.UPIVPA)
+		pdf.Ln(8)
+
+		opts := gofpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader("upi_qr", opts, bytes.NewReader(qrPNG))
+		pdf.ImageOptions("upi_qr", 44, pdf.GetY(), 60, 60, false, opts, 0, "")
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
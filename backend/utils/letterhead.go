@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"bytes"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Letterhead is the property branding printed at the top of receipts and invoices
+type Letterhead struct {
+	Name     string
+	Address  string
+	Phone    string
+	Email    string
+	GSTIN    string
+	Logo     []byte // PNG or JPEG; optional
+	LogoType string // "PNG" or "JPG"
+}
+
+// drawLetterhead prints the logo on the left and the property's name, address and contacts beside it.
+// Core PDF fonts are cp1252, so text goes through tr to keep accents and drop unsupported runes.
+func drawLetterhead(pdf *gofpdf.Fpdf, head Letterhead, tr func(string) string) {
+	left, top, _, _ := pdf.GetMargins()
+	textX := left
+
+	if len(head.Logo) > 0 {
+		opts := gofpdf.ImageOptions{ImageType: head.LogoType}
+		pdf.RegisterImageOptionsReader("logo", opts, bytes.NewReader(head.Logo))
+		if pdf.Ok() {
+			pdf.ImageOptions("logo", left, top, 20, 0, false, opts, 0, "")
+			textX = left + 24
+		} else {
+			// A corrupt logo should not cost the tenant their receipt
+			pdf.ClearError()
+		}
+	}
+
+	pdf.SetXY(textX, top)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Arial", "B", 13)
+	pdf.CellFormat(0, 7, tr(head.Name), "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Arial", "", 8)
+	lines := []string{}
+	if head.Address != "" {
+		lines = append(lines, head.Address)
+	}
+	contact := head.Phone
+	if head.Email != "" {
+		if contact != "" {
+			contact += "  |  "
+		}
+		contact += head.Email
+	}
+	if contact != "" {
+		lines = append(lines, contact)
+	}
+	if head.GSTIN != "" {
+		lines = append(lines, "GSTIN: "+head.GSTIN)
+	}
+	for _, line := range lines {
+		pdf.SetX(textX)
+		pdf.MultiCell(0, 4, tr(line), "", "L", false)
+	}
+
+	// Keep clear of the logo, then rule off the header
+	if y := top + 22; pdf.GetY() < y && len(head.Logo) > 0 {
+		pdf.SetY(y)
+	}
+	pdf.Ln(2)
+	pageW, _ := pdf.GetPageSize()
+	pdf.SetDrawColor(24, 144, 255)
+	pdf.Line(left, pdf.GetY(), pageW-left, pdf.GetY())
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.Ln(4)
+}
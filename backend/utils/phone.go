@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"errors"
+	"net/mail"
+	"strings"
+)
+
+// DefaultCountryCode is assumed for numbers entered without one (Indian PGs)
+const DefaultCountryCode = "91"
+
+// NormalizePhone converts the ways people type a number ("98450 12345", "09845012345",
+// "+91-98450-12345", "whatsapp:+919845012345") into E.164, e.g. "+919845012345".
+func NormalizePhone(raw string) (string, error) {
+	raw = strings.TrimPrefix(strings.TrimSpace(raw), "whatsapp:")
+
+	international := strings.HasPrefix(raw, "+")
+	var digits strings.Builder
+	for _, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.' || (r == '+' && digits.Len() == 0):
+		default:
+			return "", errors.New("invalid phone number: unexpected characters")
+		}
+	}
+	d := digits.String()
+
+	// Without a + or 00 only Indian mobiles are accepted: 98450 12345, 098450 12345, 91 98450 12345
+	switch {
+	case international:
+	case strings.HasPrefix(d, "00"):
+		d = d[2:]
+	case len(d) == 10:
+		d = DefaultCountryCode + d
+	case len(d) == 11 && strings.HasPrefix(d, "0"):
+		d = DefaultCountryCode + d[1:]
+	case len(d) == 12 && strings.HasPrefix(d, DefaultCountryCode):
+	default:
+		return "", errors.New("invalid phone number: expected a 10-digit mobile or +<country code><number>")
+	}
+
+	// E.164: a country code plus the subscriber number, 10 to 15 digits in all
+	if len(d) < 10 || len(d) > 15 || d[0] == '0' {
+		return "", errors.New("invalid phone number: expected a 10-digit mobile or +<country code><number>")
+	}
+	// Indian mobiles are 10 digits starting with 6-9
+	if strings.HasPrefix(d, DefaultCountryCode) && (len(d) != 12 || d[2] < '6') {
+		return "", errors.New("invalid phone number: Indian mobile numbers have 10 digits starting with 6-9")
+	}
+
+	return "+" + d, nil
+}
+
+// IsValidEmail accepts a bare address (no display name)
+func IsValidEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email && strings.Contains(email[strings.LastIndex(email, "@"):], ".")
+}
@@ -0,0 +1,48 @@
+package utils
+
+import "testing"
+
+func TestNormalizePhone(t *testing.T) {
+	cases := []struct {
+		name string
+		raw  string
+		want string // empty means the number is rejected
+	}{
+		{"ten digit mobile", "98450 12345", "+919845012345"},
+		{"leading 0", "09845012345", "+919845012345"},
+		{"leading 00", "00919845012345", "+919845012345"},
+		{"00 foreign", "0044 7911 123456", "+447911123456"},
+		{"+91 with dashes", "+91-98450-12345", "+919845012345"},
+		{"91 without plus", "91 98450 12345", "+919845012345"},
+		{"whatsapp prefix", "whatsapp:+919845012345", "+919845012345"},
+		{"foreign", "+1 (415) 555-0132", "+14155550132"},
+		{"longest E.164", "+123456789012345", "+123456789012345"},
+
+		{"eight digit local", "98450123", ""},
+		{"nine digit local", "984501234", ""},
+		{"too short international", "+12345678", ""},
+		{"too long international", "+1234567890123456", ""},
+		{"+91 too short", "+91 98450 1234", ""},
+		{"+91 too long", "+91 98450 123456", ""},
+		{"+91 landline", "+91 22 2345 6789", ""},
+		{"0 too short", "0984501234", ""},
+		{"00 too short", "00 12 345", ""},
+		{"letters", "98450-ABCDE", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NormalizePhone(tc.raw)
+			if tc.want == "" {
+				if err == nil {
+					t.Errorf("NormalizePhone(%q) = %q, want an error", tc.raw, got)
+				}
+				return
+			}
+			if err != nil || got != tc.want {
+				t.Errorf("NormalizePhone(%q) = %q, %v; want %q", tc.raw, got, err, tc.want)
+			}
+		})
+	}
+}
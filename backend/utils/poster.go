@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// PosterPage is one printable QR poster: the property-wide one or one per room
+type PosterPage struct {
+	Heading    string
+	Subheading string
+	RoomNo     string // Empty on the property-wide poster
+	URL        string
+	QR         []byte // PNG
+}
+
+// GenerateQRPoster renders A4 posters, one per page, with the property letterhead, a large QR code and
+// the link spelled out for phones that cannot scan
+func GenerateQRPoster(head Letterhead, pages []PosterPage) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetAutoPageBreak(false, 0)
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pageW, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+
+	for i, page := range pages {
+		pdf.AddPage()
+		drawLetterhead(pdf, head, tr)
+
+		// 1. Heading
+		pdf.Ln(8)
+		pdf.SetTextColor(24, 144, 255)
+		pdf.SetFont("Arial", "B", 26)
+		pdf.MultiCell(0, 12, tr(page.Heading), "", "C", false)
+		pdf.SetTextColor(80, 80, 80)
+		pdf.SetFont("Arial", "", 14)
+		pdf.MultiCell(0, 8, tr(page.Subheading), "", "C", false)
+		pdf.Ln(6)
+
+		// 2. QR code, centred
+		const qrSize = 120.0
+		name := fmt.Sprintf("qr%d", i)
+		opts := gofpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader(name, opts, bytes.NewReader(page.QR))
+		y := pdf.GetY()
+		pdf.ImageOptions(name, (pageW-qrSize)/2, y, qrSize, qrSize, false, opts, 0, "")
+		pdf.SetY(y + qrSize + 6)
+
+		// 3. Room label and fallback link
+		pdf.SetTextColor(0, 0, 0)
+		if page.RoomNo != "" {
+			pdf.SetFont("Arial", "B", 22)
+			pdf.CellFormat(0, 12, tr("Room "+page.RoomNo), "", 1, "C", false, 0, "")
+		}
+		pdf.SetFont("Arial", "", 8)
+		pdf.SetTextColor(120, 120, 120)
+		pdf.SetX(left)
+		pdf.MultiCell(pageW-left-right, 4, page.URL, "", "C", false)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
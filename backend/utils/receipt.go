@@ -1,59 +1,164 @@
 package utils
 
 import (
-	"os"
-	"path/filepath"
+	"bytes"
+	"math"
 	"pg-manager-backend/models"
 	"strconv"
 
 	"github.com/jung-kurt/gofpdf"
 )
 
-func GenerateReceipt(payment models.Payment, tenantName string) (string, error) {
+// ReceiptData is everything printed on a receipt, gathered by the services layer
+type ReceiptData struct {
+	Payment     models.Payment
+	TenantName  string
+	RoomNo      string
+	Letterhead  Letterhead
+	Allocations []models.PaymentAllocation
+	AgainstNo   string // Receipt number of the payment a reversal/refund offsets
+	VerifyURL   string // Encoded in the QR so anyone holding the paper can check it is genuine
+}
+
+// ReceiptNumber is the series number, or the legacy "#PAY-<id>" for payments made before numbering
+func ReceiptNumber(payment models.Payment) string {
+	if payment.ReceiptNo != "" {
+		return payment.ReceiptNo
+	}
+	return "#PAY-" + strconv.FormatUint(uint64(payment.ID), 10)
+}
+
+func inr(amount float64) string {
+	return "INR " + strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// GenerateReceipt renders the receipt PDF; the caller decides where it is stored
+func GenerateReceipt(data ReceiptData) ([]byte, error) {
+	payment := data.Payment
+
 	// P = Portrait, mm = millimeters, A5 size
 	pdf := gofpdf.New("P", "mm", "A5", "")
 	pdf.AddPage()
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
 
-	// 1. Branding - Direct string usage
-	pdf.SetFont("Arial", "B", 16)
+	// 1. Branding
+	drawLetterhead(pdf, data.Letterhead, tr)
+
+	title, amountLabel := "PAYMENT RECEIPT", "Amount Received"
+	switch {
+	case payment.PaymentType == "Reversal":
+		title, amountLabel = "REVERSAL NOTE", "Amount Reversed"
+	case payment.Amount < 0:
+		title, amountLabel = "REFUND VOUCHER", "Amount Refunded"
+	}
+
+	pdf.SetFont("Arial", "B", 14)
 	pdf.SetTextColor(24, 144, 255)
-	pdf.Cell(0, 10, "PAYMENT RECEIPT")
-	pdf.Ln(12)
+	pdf.CellFormat(0, 8, title, "", 1, "C", false, 0, "")
+	pdf.Ln(2)
 
-	// 2. Body - Used strconv for ID conversion (faster than Sprintf)
+	// 2. Receipt number and date on one line
 	pdf.SetTextColor(0, 0, 0)
-	pdf.SetFont("Arial", "", 10)
-	pdf.Cell(0, 10, "Receipt No: #PAY-"+strconv.FormatUint(uint64(payment.ID), 10))
-	pdf.Ln(6)
-	pdf.Cell(0, 10, "Date: "+payment.Date.Format("02-Jan-2006"))
-	pdf.Ln(10)
+	pdf.SetFont("Arial", "", 9)
+	pdf.CellFormat(64, 6, "Receipt No: "+ReceiptNumber(payment), "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "Date: "+payment.Date.Format("02-Jan-2006"), "", 1, "R", false, 0, "")
+	pdf.Ln(2)
 
-	// Table Header
+	// 3. Payment details as label/value rows
 	pdf.SetFillColor(245, 247, 250)
-	pdf.CellFormat(0, 10, "Payment Details", "1", 1, "L", true, 0, "")
+	pdf.SetFont("Arial", "B", 9)
+	pdf.CellFormat(0, 7, "Payment Details", "1", 1, "L", true, 0, "")
+	pdf.SetFont("Arial", "", 9)
 
-	// Details
-	pdf.Cell(0, 10, "Tenant Name: "+tenantName)
-	pdf.Ln(8)
-	pdf.Cell(0, 10, "Particulars: Room Rent / Maintenance")
-	pdf.Ln(8)
+	row := func(label, value string) {
+		if value == "" {
+			return
+		}
+		pdf.CellFormat(38, 6, label, "", 0, "L", false, 0, "")
+		pdf.MultiCell(0, 6, tr(value), "", "L", false)
+	}
 
-	// Amount
-	pdf.SetFont("Arial", "B", 12)
-	pdf.Cell(0, 10, "Total Amount: INR "+strconv.FormatFloat(payment.Amount, 'f', 2, 64))
-	pdf.Ln(12)
+	row("Received From", data.TenantName)
+	row("Room", data.RoomNo)
+	row("Payment Method", payment.Method)
+	row("Payment Type", payment.PaymentType)
+	row("Transaction Ref", payment.Reference)
+	row("Against Receipt", data.AgainstNo)
+	row("Reason", payment.Reason)
+	pdf.Ln(2)
 
-	// 3. Save Logic
-	fileName := "receipt_" + strconv.FormatUint(uint64(payment.ID), 10) + ".pdf"
-	dir := filepath.Join("public", "receipts")
+	// 4. Amount, in figures and in words
+	pdf.SetFont("Arial", "B", 11)
+	pdf.CellFormat(0, 8, amountLabel+": "+inr(math.Abs(payment.Amount)), "", 1, "L", false, 0, "")
+	pdf.SetFont("Arial", "I", 8)
+	pdf.MultiCell(0, 4, AmountInWords(payment.Amount), "", "L", false)
+	pdf.Ln(3)
 
-	// Ensure directory exists
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return "", err
+	// 5. Allocation breakdown: which invoices this payment settled and what is carried forward
+	pdf.SetFont("Arial", "", 9)
+	if len(data.Allocations) > 0 || payment.Unallocated >= 0.01 {
+		pdf.SetFont("Arial", "B", 9)
+		pdf.CellFormat(0, 7, "Allocation", "1", 1, "L", true, 0, "")
+		pdf.SetFont("Arial", "", 9)
+		for _, alloc := range data.Allocations {
+			// Invoices raised before numbering series existed only have their UPI reference
+			number := alloc.Invoice.Number
+			if number == "" {
+				number = alloc.Invoice.Reference
+			}
+			label := number + " (" + alloc.Invoice.Description + ")"
+			pdf.CellFormat(90, 6, tr(label), "", 0, "L", false, 0, "")
+			pdf.CellFormat(0, 6, inr(alloc.Amount), "", 1, "R", false, 0, "")
+		}
+		if payment.Unallocated >= 0.01 {
+			pdf.CellFormat(90, 6, "Advance credit carried forward", "", 0, "L", false, 0, "")
+			pdf.CellFormat(0, 6, inr(payment.Unallocated), "", 1, "R", false, 0, "")
+		}
+		pdf.Ln(2)
 	}
 
-	fullPath := filepath.Join(dir, fileName)
-	err := pdf.OutputFileAndClose(fullPath)
+	// 6. Where the tenant stands after this entry
+	if payment.BalanceAfter != nil {
+		balance := *payment.BalanceAfter
+		label, value := "Balance Due", balance
+		if balance < 0 {
+			label, value = "Advance Credit", -balance
+		}
+		pdf.SetFont("Arial", "B", 9)
+		pdf.CellFormat(90, 7, label+" after this payment", "T", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 7, inr(value), "T", 1, "R", false, 0, "")
+		pdf.Ln(2)
+	}
+
+	// Reversed originals are re-rendered with a stamp instead of being deleted
+	if payment.Status == "reversed" && payment.ReversedAt != nil {
+		pdf.SetFont("Arial", "B", 10)
+		pdf.SetTextColor(220, 38, 38)
+		pdf.Cell(0, 8, "REVERSED on "+payment.ReversedAt.Format("02-Jan-2006")+" - see reversal note")
+		pdf.Ln(10)
+		pdf.SetTextColor(0, 0, 0)
+	}
 
-	return fileName, err
+	// 7. Verification QR at the foot of the page
+	if data.VerifyURL != "" {
+		qrPNG, err := GenerateUPIQR(data.VerifyURL, 256)
+		if err != nil {
+			return nil, err
+		}
+		left, _, _, _ := pdf.GetMargins()
+		y := pdf.GetY() + 2
+		opts := gofpdf.ImageOptions{ImageType: "PNG"}
+		pdf.RegisterImageOptionsReader("verify_qr", opts, bytes.NewReader(qrPNG))
+		pdf.ImageOptions("verify_qr", left, y, 24, 24, false, opts, 0, "")
+
+		pdf.SetXY(left+28, y+6)
+		pdf.SetFont("Arial", "", 7)
+		pdf.MultiCell(0, 4, "Scan to verify this receipt online.\nThis is a computer-generated receipt and needs no signature.", "", "L", false)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"bytes"
+	"compress/zlib"
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"pg-manager-backend/models"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+var (
+	pdfStream = regexp.MustCompile(`(?s)stream\r?\n(.*?)\r?\nendstream`)
+	pdfText   = regexp.MustCompile(`\(((?:\\.|[^\\)])*)\) ?Tj|/(\w+) Do`)
+	pdfEscape = strings.NewReplacer(`\(`, "(", `\)`, ")", `\\`, `\`, `\r`, "")
+)
+
+// pageText pulls the text shown on each page, one Tj per line in drawing order; images show as [image]
+func pageText(t *testing.T, pdf []byte) string {
+	t.Helper()
+	var out strings.Builder
+	for _, m := range pdfStream.FindAllSubmatch(pdf, -1) {
+		r, err := zlib.NewReader(bytes.NewReader(m[1]))
+		if err != nil {
+			continue
+		}
+		content, err := io.ReadAll(r)
+		if err != nil || !bytes.Contains(content, []byte("BT")) {
+			continue
+		}
+		for _, op := range pdfText.FindAllSubmatch(content, -1) {
+			if op[2] != nil {
+				out.WriteString("[image]\n")
+				continue
+			}
+			out.WriteString(pdfEscape.Replace(string(op[1])) + "\n")
+		}
+	}
+	return out.String()
+}
+
+// assertGolden compares against testdata/<name>.golden; run with -update to accept a deliberate change
+func assertGolden(t *testing.T, name, got string) {
+	t.Helper()
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("missing golden file, run go test -update: %v", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s changed:\n--- got\n%s\n--- want\n%s", name, got, want)
+	}
+}
+
+func testLogo(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	for x := 0; x < 40; x++ {
+		for y := 0; y < 40; y++ {
+			img.Set(x, y, color.RGBA{24, 144, 255, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGenerateReceiptGolden(t *testing.T) {
+	date := time.Date(2025, 1, 5, 10, 30, 0, 0, time.UTC)
+	reversedAt := time.Date(2025, 1, 7, 9, 0, 0, 0, time.UTC)
+	balance := func(v float64) *float64 { return &v }
+
+	head := Letterhead{
+		Name:    "Sri Sai PG",
+		Address: "12, 4th Cross, Koramangala, Bengaluru 560034",
+		Phone:   "+919845012345",
+		Email:   "stay@srisaipg.in",
+		GSTIN:   "29ABCDE1234F1Z5",
+	}
+	noGSTIN := head
+	noGSTIN.GSTIN = ""
+	withLogo := noGSTIN
+	withLogo.Logo, withLogo.LogoType = testLogo(t), "PNG"
+
+	rent := models.Invoice{Reference: "INV-000045", Number: "SRI/INV/24-25/000045", Description: "Rent Jan 2025"}
+	unnumbered := models.Invoice{Reference: "INV-000044", Description: "Rent Dec 2024"}
+	payment := models.Payment{
+		ID: 101, ReceiptNo: "SRI/24-25/000123", Amount: 12500.50, PaymentType: "Rent-Payment",
+		Method: "UPI", Reference: "412345678901", Date: date, Status: "completed", BalanceAfter: balance(0),
+	}
+
+	tests := []struct {
+		name string
+		data ReceiptData
+	}{
+		{"receipt", ReceiptData{
+			Payment: payment, TenantName: "Ravi Kumar", RoomNo: "204", Letterhead: head,
+			Allocations: []models.PaymentAllocation{{Invoice: rent, Amount: 12500.50}},
+			VerifyURL:   "https://pg.example.com/verify/receipt/101?sig=abc",
+		}},
+		{"receipt_no_gstin", ReceiptData{Payment: payment, TenantName: "Ravi Kumar", RoomNo: "204", Letterhead: noGSTIN}},
+		{"receipt_logo", ReceiptData{Payment: payment, TenantName: "Ravi Kumar", RoomNo: "204", Letterhead: withLogo}},
+		{"receipt_advance_credit", ReceiptData{
+			Payment: models.Payment{
+				ID: 102, ReceiptNo: "SRI/24-25/000124", Amount: 20000, PaymentType: "Rent-Payment", Method: "Cash",
+				Date: date, Status: "completed", Unallocated: 7500, BalanceAfter: balance(-7500),
+			},
+			TenantName: "Ravi Kumar", RoomNo: "204", Letterhead: head,
+			Allocations: []models.PaymentAllocation{{Invoice: unnumbered, Amount: 12500}},
+		}},
+		{"reversal_note", ReceiptData{
+			Payment: models.Payment{
+				ID: 103, ReceiptNo: "SRI/24-25/000125", Amount: -12500.50, PaymentType: "Reversal", Method: "UPI",
+				Date: reversedAt, Status: "completed", Reason: "Credited to the wrong tenant", BalanceAfter: balance(12500.50),
+			},
+			TenantName: "Ravi Kumar", RoomNo: "204", Letterhead: head, AgainstNo: "SRI/24-25/000123",
+		}},
+		{"refund_voucher", ReceiptData{
+			Payment: models.Payment{
+				ID: 104, ReceiptNo: "SRI/24-25/000126", Amount: -5000, PaymentType: "Deposit-Refund", Method: "Bank Transfer",
+				Date: date, Status: "completed", Reason: "Deposit returned on vacating",
+			},
+			TenantName: "Ravi Kumar", RoomNo: "204", Letterhead: head,
+		}},
+		{"receipt_reversed", ReceiptData{
+			Payment: func() models.Payment {
+				p := payment
+				p.Status, p.ReversedAt = "reversed", &reversedAt
+				return p
+			}(),
+			TenantName: "Ravi Kumar", RoomNo: "204", Letterhead: head,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pdf, err := GenerateReceipt(tt.data)
+			if err != nil {
+				t.Fatalf("GenerateReceipt: %v", err)
+			}
+			assertGolden(t, tt.name, pageText(t, pdf))
+		})
+	}
+}
+
+func TestAmountInWords(t *testing.T) {
+	tests := []struct {
+		amount float64
+		want   string
+	}{
+		{0, "Rupees Zero Only"},
+		{0.50, "Rupees Zero and Fifty Paise Only"},
+		{1, "Rupees One Only"},
+		{105, "Rupees One Hundred and Five Only"},
+		{100000, "Rupees One Lakh Only"},
+		{10000000, "Rupees One Crore Only"},
+		{12500.50, "Rupees Twelve Thousand Five Hundred and Fifty Paise Only"},
+		{-8000, "Rupees Eight Thousand Only"},
+		{123456789.99, "Rupees Twelve Crore Thirty Four Lakh Fifty Six Thousand Seven Hundred and Eighty Nine and Ninety Nine Paise Only"},
+	}
+
+	for _, tt := range tests {
+		if got := AmountInWords(tt.amount); got != tt.want {
+			t.Errorf("AmountInWords(%v) = %q, want %q", tt.amount, got, tt.want)
+		}
+	}
+}
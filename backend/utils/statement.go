@@ -0,0 +1,136 @@
+package utils
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// StatementLine is one charge or payment on a tenant statement
+type StatementLine struct {
+	Date        time.Time
+	Reference   string
+	Description string
+	Charge      float64
+	Payment     float64 // Negative for refunds and reversals
+	Balance     float64 // Running balance after this line
+}
+
+// StatementData is a tenant's account activity for a period
+type StatementData struct {
+	Letterhead     Letterhead
+	TenantName     string
+	RoomNo         string
+	From, To       time.Time
+	OpeningBalance float64
+	ClosingBalance float64
+	TotalCharges   float64
+	TotalPayments  float64
+	Lines          []StatementLine
+}
+
+// balanceLabel shows credit balances as "CR" the way bank statements do
+func balanceLabel(balance float64) string {
+	if balance < 0 {
+		return inr(-balance) + " CR"
+	}
+	return inr(balance)
+}
+
+// GenerateStatement renders an A4 statement of account with a running balance
+func GenerateStatement(data StatementData) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	// 1. Branding
+	drawLetterhead(pdf, data.Letterhead, tr)
+
+	pdf.SetFont("Arial", "B", 14)
+	pdf.SetTextColor(24, 144, 255)
+	pdf.CellFormat(0, 8, "STATEMENT OF ACCOUNT", "", 1, "C", false, 0, "")
+	pdf.Ln(2)
+
+	// 2. Who and when
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Arial", "", 10)
+	pdf.CellFormat(110, 6, tr("Tenant: "+data.TenantName), "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "Period: "+data.From.Format("02-Jan-2006")+" to "+data.To.Format("02-Jan-2006"), "", 1, "R", false, 0, "")
+	if data.RoomNo != "" {
+		pdf.CellFormat(0, 6, tr("Room: "+data.RoomNo), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(3)
+
+	// 3. Table
+	widths := []float64{24, 42, 58, 22, 22, 22}
+	header := []string{"Date", "Reference", "Description", "Charges", "Payments", "Balance"}
+	drawHeader := func() {
+		pdf.SetFont("Arial", "B", 9)
+		pdf.SetFillColor(245, 247, 250)
+		for i, title := range header {
+			align := "L"
+			if i >= 3 {
+				align = "R"
+			}
+			pdf.CellFormat(widths[i], 7, title, "1", 0, align, true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Arial", "", 8)
+	}
+	pdf.SetHeaderFunc(func() {
+		if pdf.PageNo() > 1 {
+			drawHeader()
+		}
+	})
+	drawHeader()
+
+	// Long descriptions are cut to the column rather than spilling into the next one
+	fit := func(text string, width float64) string {
+		text = tr(text)
+		if pdf.GetStringWidth(text) <= width-2 {
+			return text
+		}
+		for len(text) > 0 && pdf.GetStringWidth(text+"...") > width-2 {
+			text = text[:len(text)-1]
+		}
+		return text + "..."
+	}
+
+	amount := func(v float64) string {
+		if v == 0 {
+			return ""
+		}
+		return inr(v)
+	}
+
+	pdf.CellFormat(widths[0]+widths[1]+widths[2]+widths[3]+widths[4], 6, "Opening balance", "1", 0, "L", false, 0, "")
+	pdf.CellFormat(widths[5], 6, balanceLabel(data.OpeningBalance), "1", 1, "R", false, 0, "")
+
+	for _, line := range data.Lines {
+		pdf.CellFormat(widths[0], 6, line.Date.Format("02-Jan-2006"), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[1], 6, fit(line.Reference, widths[1]), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[2], 6, fit(line.Description, widths[2]), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(widths[3], 6, amount(line.Charge), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[4], 6, amount(line.Payment), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(widths[5], 6, balanceLabel(line.Balance), "1", 1, "R", false, 0, "")
+	}
+
+	// 4. Totals
+	pdf.SetFont("Arial", "B", 9)
+	pdf.CellFormat(widths[0]+widths[1]+widths[2], 7, "Totals / Closing balance", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(widths[3], 7, inr(data.TotalCharges), "1", 0, "R", true, 0, "")
+	pdf.CellFormat(widths[4], 7, inr(data.TotalPayments), "1", 0, "R", true, 0, "")
+	pdf.CellFormat(widths[5], 7, balanceLabel(data.ClosingBalance), "1", 1, "R", true, 0, "")
+
+	pdf.Ln(4)
+	pdf.SetFont("Arial", "I", 8)
+	pdf.MultiCell(0, 4, "CR = advance credit held for the tenant. Generated on "+time.Now().Format("02-Jan-2006 15:04")+".", "", "L", false)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
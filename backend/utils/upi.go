@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/qr"
+)
+
+var (
+	vpaPattern = regexp.MustCompile(`^[a-zA-Z0-9.\-_]{2,256}@[a-zA-Z]{2,64}$`)
+	utrPattern = regexp.MustCompile(`^[0-9]{12}$`)
+)
+
+// IsValidVPA checks the "name@bank" shape of a UPI virtual payment address
+func IsValidVPA(vpa string) bool {
+	return vpaPattern.MatchString(vpa)
+}
+
+// IsValidUTR checks for the 12-digit UTR / RRN that UPI apps show after a transfer
+func IsValidUTR(utr string) bool {
+	return utrPattern.MatchString(utr)
+}
+
+// BuildUPIIntent returns a upi://pay deep link for the exact amount, tagged with the invoice reference
+func BuildUPIIntent(vpa, payeeName string, amount float64, reference, note string) string {
+	// UPI apps are picky: spaces must be %20 (not '+') and the VPA's '@' must stay literal
+	escape := func(v string) string {
+		return strings.NewReplacer("+", "%20", "%40", "@").Replace(url.QueryEscape(v))
+	}
+
+	params := []string{
+		"pa=" + escape(vpa),
+		"pn=" + escape(payeeName),
+		"am=" + strconv.FormatFloat(amount, 'f', 2, 64),
+		"cu=INR",
+		"tr=" + escape(reference),
+		"tn=" + escape(note),
+	}
+	return "upi://pay?" + strings.Join(params, "&")
+}
+
+// GenerateUPIQR encodes a UPI intent as a square PNG QR code of the given pixel size
+func GenerateUPIQR(intent string, size int) ([]byte, error) {
+	code, err := qr.Encode(intent, qr.M, qr.Auto)
+	if err != nil {
+		return nil, fmt.Errorf("qr encode failed: %v", err)
+	}
+
+	scaled, err := barcode.Scale(code, size, size)
+	if err != nil {
+		return nil, fmt.Errorf("qr scale failed: %v", err)
+	}
+
+	// The QR image is 16-bit grey, which gofpdf cannot embed; redraw it as 8-bit
+	gray := image.NewGray(scaled.Bounds())
+	draw.Draw(gray, gray.Bounds(), scaled, scaled.Bounds().Min, draw.Src)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, gray); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
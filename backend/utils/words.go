@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"math"
+	"strings"
+)
+
+var (
+	onesWords = []string{"", "One", "Two", "Three", "Four", "Five", "Six", "Seven", "Eight", "Nine", "Ten",
+		"Eleven", "Twelve", "Thirteen", "Fourteen", "Fifteen", "Sixteen", "Seventeen", "Eighteen", "Nineteen"}
+	tensWords = []string{"", "", "Twenty", "Thirty", "Forty", "Fifty", "Sixty", "Seventy", "Eighty", "Ninety"}
+)
+
+// belowHundred spells 0-99; zero is an empty string
+func belowHundred(n int64) string {
+	if n < 20 {
+		return onesWords[n]
+	}
+	if n%10 == 0 {
+		return tensWords[n/10]
+	}
+	return tensWords[n/10] + " " + onesWords[n%10]
+}
+
+// integerInWords spells a whole number using the Indian system (thousand, lakh, crore)
+func integerInWords(n int64) string {
+	if n == 0 {
+		return "Zero"
+	}
+
+	var parts []string
+	if n >= 10000000 {
+		parts = append(parts, integerInWords(n/10000000)+" Crore")
+		n %= 10000000
+	}
+	for _, unit := range []struct {
+		value int64
+		name  string
+	}{{100000, "Lakh"}, {1000, "Thousand"}, {100, "Hundred"}} {
+		if n >= unit.value {
+			parts = append(parts, belowHundred(n/unit.value)+" "+unit.name)
+			n %= unit.value
+		}
+	}
+	if n > 0 {
+		if len(parts) > 0 {
+			parts = append(parts, "and")
+		}
+		parts = append(parts, belowHundred(n))
+	}
+	return strings.Join(parts, " ")
+}
+
+// AmountInWords spells a rupee amount for receipts, e.g. 12500.50 → "Rupees Twelve Thousand Five Hundred and Fifty Paise Only"
+func AmountInWords(amount float64) string {
+	paise := int64(math.Round(math.Abs(amount) * 100))
+	rupees, rem := paise/100, paise%100
+
+	words := "Rupees " + integerInWords(rupees)
+	if rem > 0 {
+		words += " and " + belowHundred(rem) + " Paise"
+	}
+	return words + " Only"
+}
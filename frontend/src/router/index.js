@@ -29,6 +29,7 @@ const routes = [
     ]
   },
   { path: '/help/:id', name: 'PublicHelp', component: PublicComplaints },
+  { path: '/feedback/:id', name: 'PublicFeedback', component: () => import('../views/ComplaintFeedback.vue') },
   // Move redirect to the bottom to avoid catching the /help route
   { path: '/:pathMatch(.*)*', redirect: '/login' } 
 ];
@@ -43,7 +44,7 @@ router.beforeEach((to, from, next) => {
   const token = localStorage.getItem('token');
   
   // Allow access to Login AND the Public Help page without a token
-  if (to.name !== 'Login' && to.name !== 'PublicHelp' && !token) {
+  if (to.name !== 'Login' && to.name !== 'PublicHelp' && to.name !== 'PublicFeedback' && !token) {
     next({ name: 'Login' });
   } else {
     next();